@@ -2,6 +2,9 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/Kevin-Rudy/goping/pkg/pinger"
 	"github.com/Kevin-Rudy/goping/pkg/tui"
@@ -24,7 +27,10 @@ func runApp(c *cli.Context) error {
 	}
 
 	// 构建配置
-	appConfig := buildConfigFromCLI(c)
+	appConfig, err := buildConfigFromCLI(c)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("配置验证失败: %v", err), 1)
+	}
 
 	// 验证配置
 	if err := validateConfig(appConfig); err != nil {
@@ -54,8 +60,12 @@ func runApp(c *cli.Context) error {
 	// 创建并启动TUI实例 - 使用新的签名
 	tuiInstance := tui.NewTUI(pingerInstance, appConfig.Targets, appConfig.TUIConfig, appConfig.PingerConfig)
 
+	// 收到终止信号时退出TUI并停止ping引擎
+	ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// 启动TUI界面 - 这会阻塞直到用户退出
-	if err := tuiInstance.Run(); err != nil {
+	if err := tuiInstance.RunContext(ctx); err != nil {
 		return cli.Exit(fmt.Sprintf("TUI运行出错: %v", err), 1)
 	}
 
@@ -68,5 +78,12 @@ func printRunningConfig(config *AppConfig) {
 	fmt.Printf("目标地址: %v\n", config.Targets)
 	fmt.Printf("ping间隔: %v\n", config.PingerConfig.Interval)
 	fmt.Printf("ping超时: %v\n", config.PingerConfig.Timeout)
+	fmt.Printf("迟到宽限: %v\n", config.PingerConfig.LateGrace)
+	for _, target := range config.Targets {
+		if _, exists := config.PingerConfig.Overrides[target]; exists {
+			interval, timeout := config.PingerConfig.TargetSettings(target)
+			fmt.Printf("  %s: 间隔 %v, 超时 %v\n", target, interval, timeout)
+		}
+	}
 	fmt.Printf("缓冲区大小: %d\n", config.TUIConfig.MaxHistorySize)
 }
@@ -2,9 +2,11 @@ package main
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Kevin-Rudy/goping/pkg/pinger"
+	"github.com/Kevin-Rudy/goping/pkg/tui"
 	"github.com/urfave/cli/v2"
 )
 
@@ -54,12 +56,45 @@ func createCliFlags() []cli.Flag {
 			Value:   3 * time.Second,
 			Usage:   "ping超时时间 (例如: 3s, 1000ms)",
 		},
+		&cli.StringSliceFlag{
+			Name:  "target-interval",
+			Usage: "为单个目标设置ping间隔，可重复使用 (例如: 192.168.1.1=100ms)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "target-timeout",
+			Usage: "为单个目标设置ping超时，可重复使用 (例如: example.com=10s)",
+		},
+		&cli.DurationFlag{
+			Name:  "late-grace",
+			Value: 3 * time.Second,
+			Usage: "超时后继续接收迟到回复的宽限时间，0表示不接收 (例如: 5s)",
+		},
+		&cli.DurationFlag{
+			Name:  "resolve-interval",
+			Value: time.Minute,
+			Usage: "重新解析目标域名的间隔，地址变化时切换到新地址并记录事件，0表示只在启动时解析",
+		},
 		&cli.IntFlag{
 			Name:    "buffer",
 			Aliases: []string{"b"},
 			Value:   150,
 			Usage:   "TUI图表历史缓冲区大小",
 		},
+		&cli.DurationFlag{
+			Name:  "retention",
+			Value: 10 * time.Minute,
+			Usage: "历史数据保留时长，用于暂停后回看 (例如: 30m)",
+		},
+		&cli.DurationFlag{
+			Name:  "rollup-interval",
+			Value: 10 * time.Second,
+			Usage: "长期历史的聚合桶宽度，用于大时间窗口显示",
+		},
+		&cli.DurationFlag{
+			Name:  "rollup-retention",
+			Value: 24 * time.Hour,
+			Usage: "长期聚合历史的保留时长",
+		},
 		&cli.DurationFlag{
 			Name:    "refresh-rate",
 			Aliases: []string{"r"},
@@ -76,6 +111,61 @@ func createCliFlags() []cli.Flag {
 			Value: 5,
 			Usage: "最小图表高度",
 		},
+		&cli.BoolFlag{
+			Name:  "log-scale",
+			Usage: "Y轴使用对数刻度，适合延迟相差几个数量级的多个目标",
+		},
+		&cli.BoolFlag{
+			Name:  "relative-time",
+			Usage: "X轴以相对当前时刻的时间（如-30s）标注，默认使用时钟时间",
+		},
+		&cli.DurationFlag{
+			Name:  "warn-latency",
+			Usage: "延迟警告阈值，图表中绘制黄色参考线，最近30次探测的平均延迟超过时标黄 (例如: 50ms)",
+		},
+		&cli.DurationFlag{
+			Name:  "crit-latency",
+			Usage: "延迟严重阈值，图表中绘制红色参考线，最近30次探测的平均延迟超过时标红 (例如: 150ms)",
+		},
+		&cli.Float64Flag{
+			Name:  "warn-loss",
+			Usage: "丢包率警告阈值(%)，最近30次探测的丢包率超过时标黄",
+		},
+		&cli.Float64Flag{
+			Name:  "crit-loss",
+			Usage: "丢包率严重阈值(%)，最近30次探测的丢包率超过时标红",
+		},
+		&cli.StringFlag{
+			Name:  "theme",
+			Value: "dark",
+			Usage: "颜色主题: " + strings.Join(tui.ThemeNames(), ", ") + "（mono以线型区分目标）",
+		},
+		&cli.StringFlag{
+			Name:  "theme-file",
+			Usage: "从JSON文件加载自定义主题，\"base\"指定基础主题，其余字段覆盖其颜色",
+		},
+		&cli.BoolFlag{
+			Name:  "status-bar",
+			Value: true,
+			Usage: "在底部显示状态栏（探测引擎、间隔/超时、运行时长、探测速率、丢弃的结果数），--status-bar=false隐藏",
+		},
+		&cli.StringFlag{
+			Name:  "renderer",
+			Value: "auto",
+			Usage: "折线图字符集: " + strings.Join(tui.RendererNames(), ", ") + "（终端无法显示盲文字符时使用block或ascii）",
+		},
+		&cli.StringFlag{
+			Name:  "columns",
+			Usage: "表格中显示的统计列及顺序，逗号分隔，可选: " + strings.Join(tui.ColumnNames(), ","),
+		},
+		&cli.StringFlag{
+			Name:  "sort",
+			Usage: "表格初始排序列，可加 :asc 或 :desc 指定方向，默认降序 (例如: loss 或 avg:asc)",
+		},
+		&cli.StringFlag{
+			Name:  "event-log",
+			Usage: "将目标中断、恢复、丢包突发和地址变化等事件追加写入指定文件",
+		},
 		&cli.DurationFlag{
 			Name:  "timeout-threshold",
 			Value: 0, // 0表示自动计算
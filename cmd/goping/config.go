@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Kevin-Rudy/goping/pkg/pinger"
 	"github.com/Kevin-Rudy/goping/pkg/tui"
@@ -16,7 +18,7 @@ type AppConfig struct {
 }
 
 // buildConfigFromCLI 从命令行参数构建配置
-func buildConfigFromCLI(c *cli.Context) *AppConfig {
+func buildConfigFromCLI(c *cli.Context) (*AppConfig, error) {
 	// 构建 pinger 配置
 	pingerConfig := pinger.DefaultConfig()
 	if c.Bool("6") {
@@ -28,12 +30,41 @@ func buildConfigFromCLI(c *cli.Context) *AppConfig {
 	if c.IsSet("timeout") {
 		pingerConfig.Timeout = c.Duration("timeout")
 	}
+	if c.IsSet("late-grace") {
+		pingerConfig.LateGrace = c.Duration("late-grace")
+	}
+	if c.IsSet("resolve-interval") {
+		pingerConfig.ResolveInterval = c.Duration("resolve-interval")
+	}
+	for _, spec := range c.StringSlice("target-interval") {
+		target, interval, err := parseTargetDuration(spec)
+		if err != nil {
+			return nil, fmt.Errorf("--target-interval 参数错误: %v", err)
+		}
+		pingerConfig.SetTargetOverride(target, interval, 0)
+	}
+	for _, spec := range c.StringSlice("target-timeout") {
+		target, timeout, err := parseTargetDuration(spec)
+		if err != nil {
+			return nil, fmt.Errorf("--target-timeout 参数错误: %v", err)
+		}
+		pingerConfig.SetTargetOverride(target, 0, timeout)
+	}
 
 	// 构建 TUI 配置
 	tuiConfig := tui.DefaultConfig()
 	if c.IsSet("buffer") {
 		tuiConfig.MaxHistorySize = c.Int("buffer")
 	}
+	if c.IsSet("retention") {
+		tuiConfig.HistoryRetention = c.Duration("retention")
+	}
+	if c.IsSet("rollup-interval") {
+		tuiConfig.RollupInterval = c.Duration("rollup-interval")
+	}
+	if c.IsSet("rollup-retention") {
+		tuiConfig.RollupRetention = c.Duration("rollup-retention")
+	}
 	if c.IsSet("refresh-rate") {
 		tuiConfig.RefreshInterval = c.Duration("refresh-rate")
 	}
@@ -43,6 +74,54 @@ func buildConfigFromCLI(c *cli.Context) *AppConfig {
 	if c.IsSet("chart-height") {
 		tuiConfig.MinChartHeight = c.Int("chart-height")
 	}
+	if c.IsSet("log-scale") {
+		tuiConfig.LogScale = c.Bool("log-scale")
+	}
+	if c.IsSet("relative-time") {
+		tuiConfig.RelativeTime = c.Bool("relative-time")
+	}
+	if c.IsSet("warn-latency") {
+		tuiConfig.LatencyWarning = float64(c.Duration("warn-latency")) / float64(time.Millisecond)
+	}
+	if c.IsSet("crit-latency") {
+		tuiConfig.LatencyCritical = float64(c.Duration("crit-latency")) / float64(time.Millisecond)
+	}
+	if c.IsSet("warn-loss") {
+		tuiConfig.LossWarning = c.Float64("warn-loss")
+	}
+	if c.IsSet("crit-loss") {
+		tuiConfig.LossCritical = c.Float64("crit-loss")
+	}
+	if c.IsSet("theme") {
+		tuiConfig.Theme = c.String("theme")
+	}
+	if c.IsSet("theme-file") {
+		theme, err := tui.LoadThemeFile(c.String("theme-file"))
+		if err != nil {
+			return nil, fmt.Errorf("--theme-file 参数错误: %v", err)
+		}
+		tuiConfig.CustomTheme = theme
+	}
+	if c.IsSet("status-bar") {
+		tuiConfig.StatusBar = c.Bool("status-bar")
+	}
+	if c.IsSet("renderer") {
+		tuiConfig.Renderer = c.String("renderer")
+	}
+	if c.IsSet("columns") {
+		tuiConfig.Columns = splitList(c.String("columns"))
+	}
+	if c.IsSet("sort") {
+		column, descending, err := parseSort(c.String("sort"))
+		if err != nil {
+			return nil, fmt.Errorf("--sort 参数错误: %v", err)
+		}
+		tuiConfig.SortColumn = column
+		tuiConfig.SortDescending = descending
+	}
+	if c.IsSet("event-log") {
+		tuiConfig.EventLogFile = c.String("event-log")
+	}
 	if c.IsSet("timeout-buffer-ratio") {
 		tuiConfig.TimeoutBufferRatio = c.Float64("timeout-buffer-ratio")
 	}
@@ -51,6 +130,45 @@ func buildConfigFromCLI(c *cli.Context) *AppConfig {
 		PingerConfig: pingerConfig,
 		TUIConfig:    tuiConfig,
 		Targets:      c.Args().Slice(),
+	}, nil
+}
+
+// parseTargetDuration 解析"目标=时长"格式的参数
+// 按最后一个等号分割，以兼容包含冒号的IPv6地址
+func parseTargetDuration(spec string) (string, time.Duration, error) {
+	index := strings.LastIndex(spec, "=")
+	if index <= 0 || index == len(spec)-1 {
+		return "", 0, fmt.Errorf("'%s' 格式应为 目标=时长", spec)
+	}
+
+	duration, err := time.ParseDuration(spec[index+1:])
+	if err != nil {
+		return "", 0, fmt.Errorf("'%s' 中的时长无效: %v", spec, err)
+	}
+	return spec[:index], duration, nil
+}
+
+// splitList 解析逗号分隔的列表，忽略空白和空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseSort 解析"列[:asc|desc]"格式的排序参数，未指定方向时为降序
+func parseSort(spec string) (string, bool, error) {
+	column, direction, _ := strings.Cut(spec, ":")
+	switch direction {
+	case "", "desc":
+		return column, true, nil
+	case "asc":
+		return column, false, nil
+	default:
+		return "", false, fmt.Errorf("'%s' 中的排序方向无效，应为 asc 或 desc", spec)
 	}
 }
 
@@ -21,11 +21,9 @@ func showSystemInfo() {
 	fmt.Printf("  实现方式: %s\n", pinger.GetImplementationType())
 }
 
-// printUsageInstructions 显示TUI操作说明
+// printUsageInstructions 显示TUI操作提示
+// 完整的按键和命令列表由界面内的帮助根据按键绑定表生成，这里不再重复列出
 func printUsageInstructions() {
-	fmt.Println("操作说明:")
-	fmt.Println("  ↑/↓ 方向键  - 导航选择目标")
-	fmt.Println("  在边界继续按方向键 - 切换到全选模式")
-	fmt.Println("  q 或 Ctrl+C - 退出程序")
+	fmt.Println("操作说明: 在界面中按 ? 查看全部按键和命令")
 	fmt.Println("========================================")
 }
@@ -3,6 +3,7 @@
 package core
 
 import (
+	"context"
 	"math"
 	"time"
 )
@@ -14,8 +15,20 @@ type PingResult struct {
 	Latency     float64   // 延迟(ms)。超时或失败时为 math.NaN()
 	SendTime    time.Time // ping发送时间，用于时间对齐
 	ReceiveTime time.Time // ping接收时间，用于精确计算延迟
+	Seq         int       // 探测包序列号
+	Kind        ReplyKind // 回复分类，零值为正常回复
+	OutOfOrder  bool      // 是否晚于更大序列号的回复到达（乱序）
 }
 
+// ReplyKind 表示回复报文的分类
+type ReplyKind int
+
+const (
+	ReplyNormal    ReplyKind = iota // 正常回复（或超时），对应一个新的探测包
+	ReplyDuplicate                  // 重复回复，该序列号此前已收到过回复
+	ReplyLate                       // 迟到回复，对应的探测包此前已判定为超时
+)
+
 // PointStatus 表示数据点的状态
 type PointStatus int
 
@@ -24,6 +37,8 @@ const (
 	PointSuccess                         // 成功收到响应
 	PointTimeout                         // 超时
 	PointInterpolated                    // 插值点
+	PointLate                            // 超时后在宽限期内收到的迟到回复
+	PointRange                           // 降采样时一个显示桶内的最小或最大延迟，只用于绘制延迟范围
 )
 
 // DataPoint 表示带时间戳和状态的数据点
@@ -55,6 +70,14 @@ type Stats struct {
 	MinLatency float64 // 全局最小延迟
 	MaxLatency float64 // 全局最大延迟
 
+	// 异常回复计数
+	Duplicates  int // 重复回复数
+	Reordered   int // 乱序回复数
+	LateReplies int // 迟到回复数（超时后在宽限期内收到，不计入丢包）
+
+	// 数据源上报的健康状态
+	Health TargetHealth
+
 	// --- 用于最终显示的格式化数据 ---
 	Summary map[string]string // 汇总统计信息的键值对映射
 }
@@ -75,6 +98,56 @@ func NewStats(identifier string) *Stats {
 	}
 }
 
+// ResetCounters 清零表格统计使用的全局累加器：收发计数、Welford累加器、最大/最小值和异常回复计数
+// 图表使用的历史和数据源上报的健康状态保持不变
+func (s *Stats) ResetCounters() {
+	s.PacketsSent = 0
+	s.PacketsRecv = 0
+	s.WelfordCount = 0
+	s.WelfordMean = 0.0
+	s.WelfordM2 = 0.0
+	s.MinLatency = math.Inf(1)
+	s.MaxLatency = math.Inf(-1)
+	s.Duplicates = 0
+	s.Reordered = 0
+	s.LateReplies = 0
+}
+
+// TargetHealth 表示单个目标的健康状态
+// 用于区分"目标不可达"与"探测引擎故障"
+type TargetHealth int
+
+const (
+	HealthUnknown TargetHealth = iota // 尚未收到任何结果
+	HealthUp                          // 目标可达
+	HealthDown                        // 目标不可达（连续超时或地址解析失败）
+	HealthError                       // 探测引擎故障（如套接字创建失败），与目标本身无关
+)
+
+// String 返回健康状态的中文描述
+func (h TargetHealth) String() string {
+	switch h {
+	case HealthUp:
+		return "正常"
+	case HealthDown:
+		return "中断"
+	case HealthError:
+		return "故障"
+	default:
+		return "未知"
+	}
+}
+
+// SourceEvent 表示数据源运行过程中的事件
+// 目标健康状态发生变化、解析地址发生变化或探测出错时产生
+type SourceEvent struct {
+	Time       time.Time    // 事件发生时间
+	Identifier string       // 相关目标，为空表示数据源整体的事件
+	Health     TargetHealth // 事件发生后目标的健康状态
+	Err        error        // 引发事件的错误，健康状态正常变化时为nil
+	Address    string       // 目标新解析到的地址，仅在地址事件中非空
+}
+
 // DataSource 定义了数据源的标准接口
 // 任何监控执行器（如Pinger、下载速度测试器等）都应该实现这个接口
 type DataSource interface {
@@ -82,12 +155,54 @@ type DataSource interface {
 	// 实现者应该在独立的goroutine中持续发送PingResult数据到这个通道
 	DataStream() <-chan PingResult
 
+	// Events 返回一个只读通道，用于接收健康状态变化和错误事件
+	// 调用Stop()后该通道应该被关闭
+	Events() <-chan SourceEvent
+
 	// Start 启动数据收集
 	// 这个方法应该是非阻塞的，实际的数据收集工作在后台goroutine中进行
-	Start()
+	// 无法启动时返回错误；ctx被取消时数据源应自动停止
+	Start(ctx context.Context) error
+
+	// Health 返回指定目标当前的健康状态
+	Health(identifier string) TargetHealth
 
 	// Stop 停止数据收集并清理资源
-	// 调用此方法后，DataStream()返回的通道应该被关闭
+	// 调用此方法后，DataStream()和Events()返回的通道应该被关闭
 	// 所有相关的goroutine应该优雅地退出
 	Stop()
 }
+
+// TargetManager 定义了支持运行时增删目标的数据源扩展接口
+// 数据源可选择实现此接口，使用方通过类型断言判断是否支持
+type TargetManager interface {
+	// AddTarget 添加新的监控目标
+	// 数据源运行中时应立即开始采集该目标的数据
+	AddTarget(identifier string) error
+
+	// RemoveTarget 移除监控目标并停止对其的数据采集
+	// 已有的其他目标不受影响
+	RemoveTarget(identifier string) error
+}
+
+// DropCounter 定义了可以报告丢弃结果数的数据源扩展接口
+// 数据源可选择实现此接口，使用方通过类型断言判断是否支持
+type DropCounter interface {
+	// DroppedResults 返回因消费方处理不及时、数据通道已满而丢弃的结果数
+	DroppedResults() uint64
+}
+
+// EngineReporter 定义了可以报告探测引擎信息的数据源扩展接口
+// 数据源可选择实现此接口，使用方通过类型断言判断是否支持
+type EngineReporter interface {
+	// EngineInfo 返回数据源实际使用的探测实现方式和权限模式
+	EngineInfo() (implementation, privilege string)
+}
+
+// IntervalSetter 定义了支持运行时调整探测间隔的数据源扩展接口
+// 数据源可选择实现此接口，使用方通过类型断言判断是否支持
+type IntervalSetter interface {
+	// SetInterval 设置探测间隔，identifier为空表示修改全局间隔
+	// 新间隔从目标的下一次探测开始生效
+	SetInterval(identifier string, interval time.Duration) error
+}
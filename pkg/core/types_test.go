@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"math"
 	"testing"
 	"time"
@@ -87,7 +88,15 @@ func (m *mockDataSource) DataStream() <-chan PingResult {
 	return m.dataChan
 }
 
-func (m *mockDataSource) Start() {
+func (m *mockDataSource) Events() <-chan SourceEvent {
+	return nil
+}
+
+func (m *mockDataSource) Health(identifier string) TargetHealth {
+	return HealthUnknown
+}
+
+func (m *mockDataSource) Start(ctx context.Context) error {
 	m.started = true
 	go func() {
 		for i := 0; i < 3; i++ {
@@ -110,6 +119,7 @@ func (m *mockDataSource) Start() {
 		}
 		close(m.dataChan)
 	}()
+	return nil
 }
 
 func (m *mockDataSource) Stop() {
@@ -126,7 +136,9 @@ func TestDataSourceInterface(t *testing.T) {
 	}
 
 	// 测试启动
-	mock.Start()
+	if err := mock.Start(context.Background()); err != nil {
+		t.Errorf("Start() should not return error: %v", err)
+	}
 	if !mock.started {
 		t.Error("DataSource should be started after Start() call")
 	}
@@ -183,3 +195,44 @@ done:
 		t.Error("DataSource should be stopped after Stop() call")
 	}
 }
+
+// TestTargetHealthString 测试健康状态的描述
+func TestTargetHealthString(t *testing.T) {
+	cases := map[TargetHealth]string{
+		HealthUnknown: "未知",
+		HealthUp:      "正常",
+		HealthDown:    "中断",
+		HealthError:   "故障",
+	}
+	for health, expected := range cases {
+		if health.String() != expected {
+			t.Errorf("Expected %v to be %q, got %q", int(health), expected, health.String())
+		}
+	}
+}
+
+// TestStatsResetCounters 测试重置统计只清零累加器，保留历史和健康状态
+func TestStatsResetCounters(t *testing.T) {
+	stats := NewStats("test.com")
+	stats.History = append(stats.History, DataPoint{Timestamp: time.Now(), Value: 10, Status: PointSuccess})
+	stats.PacketsSent, stats.PacketsRecv = 10, 8
+	stats.WelfordCount, stats.WelfordMean, stats.WelfordM2 = 8, 12.5, 3.0
+	stats.MinLatency, stats.MaxLatency = 5, 20
+	stats.Duplicates, stats.Reordered, stats.LateReplies = 1, 2, 3
+	stats.Health = HealthUp
+
+	stats.ResetCounters()
+
+	if stats.PacketsSent != 0 || stats.PacketsRecv != 0 || stats.WelfordCount != 0 || stats.WelfordMean != 0 || stats.WelfordM2 != 0 {
+		t.Error("Expected packet counters and Welford accumulators to be reset")
+	}
+	if !math.IsInf(stats.MinLatency, 1) || !math.IsInf(stats.MaxLatency, -1) {
+		t.Errorf("Expected min/max to be reset to infinities, got %v/%v", stats.MinLatency, stats.MaxLatency)
+	}
+	if stats.Duplicates != 0 || stats.Reordered != 0 || stats.LateReplies != 0 {
+		t.Error("Expected anomaly counters to be reset")
+	}
+	if len(stats.History) != 1 || stats.Health != HealthUp {
+		t.Error("Expected history and health to be kept")
+	}
+}
@@ -14,6 +14,19 @@ type Config struct {
 	Interval   time.Duration // ping间隔时间
 	Timeout    time.Duration // ping超时时间
 	BufferSize int           // 数据通道缓冲区大小
+	LateGrace  time.Duration // 超时后继续等待迟到回复的宽限时间，0表示不接收迟到回复
+
+	// ResolveInterval 重新解析目标域名的间隔，地址变化时切换到新地址，0表示只在启动时解析
+	ResolveInterval time.Duration
+
+	// Overrides 按目标覆盖的探测设置，未出现的目标使用全局的Interval和Timeout
+	Overrides map[string]TargetConfig
+}
+
+// TargetConfig 单个目标的探测设置，零值字段表示沿用全局配置
+type TargetConfig struct {
+	Interval time.Duration // 该目标的ping间隔时间
+	Timeout  time.Duration // 该目标的ping超时时间
 }
 
 // DefaultConfig 返回默认配置
@@ -23,7 +36,51 @@ func DefaultConfig() *Config {
 		Interval:   200 * time.Millisecond, // 默认200ms间隔
 		Timeout:    3 * time.Second,        // 默认3秒超时
 		BufferSize: 100,                    // 默认100缓冲区大小
+		LateGrace:  3 * time.Second,        // 默认超时后再等待3秒
+
+		ResolveInterval: time.Minute, // 默认每分钟重新解析一次
+	}
+}
+
+// TargetSettings 返回指定目标实际生效的ping间隔和超时时间
+func (c *Config) TargetSettings(target string) (interval, timeout time.Duration) {
+	interval, timeout = c.Interval, c.Timeout
+	if override, exists := c.Overrides[target]; exists {
+		if override.Interval > 0 {
+			interval = override.Interval
+		}
+		if override.Timeout > 0 {
+			timeout = override.Timeout
+		}
 	}
+	return interval, timeout
+}
+
+// Clone 返回配置的副本，副本与原配置不共享按目标覆盖的设置
+func (c *Config) Clone() *Config {
+	clone := *c
+	if c.Overrides != nil {
+		clone.Overrides = make(map[string]TargetConfig, len(c.Overrides))
+		for target, override := range c.Overrides {
+			clone.Overrides[target] = override
+		}
+	}
+	return &clone
+}
+
+// SetTargetOverride 设置单个目标的ping间隔和超时时间，零值表示沿用全局配置
+func (c *Config) SetTargetOverride(target string, interval, timeout time.Duration) {
+	if c.Overrides == nil {
+		c.Overrides = make(map[string]TargetConfig)
+	}
+	override := c.Overrides[target]
+	if interval > 0 {
+		override.Interval = interval
+	}
+	if timeout > 0 {
+		override.Timeout = timeout
+	}
+	c.Overrides[target] = override
 }
 
 // GetIPProtocol 获取IP协议字符串，用于网络操作
@@ -57,25 +114,52 @@ func (c *Config) Validate() error {
 		return errors.New("IP版本必须是4或6")
 	}
 
-	if c.Interval <= 0 {
+	if err := validateProbeSettings(c.Interval, c.Timeout); err != nil {
+		return err
+	}
+
+	for target, override := range c.Overrides {
+		if override.Interval < 0 || override.Timeout < 0 {
+			return fmt.Errorf("目标 '%s' 的间隔和超时不能为负数", target)
+		}
+		interval, timeout := c.TargetSettings(target)
+		if err := validateProbeSettings(interval, timeout); err != nil {
+			return fmt.Errorf("目标 '%s' 的配置错误: %v", target, err)
+		}
+	}
+
+	if c.BufferSize <= 0 {
+		return errors.New("缓冲区大小必须大于0")
+	}
+
+	if c.LateGrace < 0 {
+		return errors.New("迟到回复宽限时间不能为负数")
+	}
+
+	if c.ResolveInterval < 0 {
+		return errors.New("重新解析间隔不能为负数")
+	}
+
+	return nil
+}
+
+// validateProbeSettings 验证ping间隔和超时时间
+func validateProbeSettings(interval, timeout time.Duration) error {
+	if interval <= 0 {
 		return errors.New("ping间隔必须大于0")
 	}
 
-	if c.Interval < 10*time.Millisecond {
+	if interval < 10*time.Millisecond {
 		return errors.New("ping间隔不能小于10ms")
 	}
 
-	if c.Timeout <= 0 {
+	if timeout <= 0 {
 		return errors.New("超时时间必须大于0")
 	}
 
-	if c.Timeout < 100*time.Millisecond {
+	if timeout < 100*time.Millisecond {
 		return errors.New("超时时间不能小于100ms")
 	}
 
-	if c.BufferSize <= 0 {
-		return errors.New("缓冲区大小必须大于0")
-	}
-
 	return nil
 }
@@ -5,6 +5,7 @@
 package pinger
 
 import (
+	"fmt"
 	"math"
 	"net"
 	"os"
@@ -17,9 +18,10 @@ import (
 )
 
 // dgramPinger Linux非特权模式的ping实现
+// 每个目标使用独立的DGRAM套接字：内核为每个套接字分配回显ID，并只把对应ID的回复交给该套接字，
+// 目标之间不会互相读走回复，重复、乱序和迟到的回复都能交给所属目标的跟踪器
 type dgramPinger struct {
 	*basePinger
-	sock4 int // IPv4 DGRAM socket
 }
 
 // newLinuxDgramPinger 创建Linux非特权模式的pinger实例
@@ -27,66 +29,81 @@ func newLinuxDgramPinger(targets []string, config *Config) (core.DataSource, err
 	p := &dgramPinger{
 		basePinger: newBasePinger(targets, config),
 	}
+	p.runTarget = p.pingTarget
 
-	// 创建DGRAM ICMP socket
-	sock, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM, syscall.IPPROTO_ICMP)
+	// 试创建DGRAM ICMP套接字，确认当前用户有权限使用（net.ipv4.ping_group_range）
+	sock, err := openDgramSocket()
 	if err != nil {
 		return nil, err
 	}
-
-	// 设置socket到结构体中
-	p.sock4 = sock
+	syscall.Close(sock)
 
 	return p, nil
 }
 
-// Start 实现core.DataSource接口，启动ping操作
-func (p *dgramPinger) Start() {
-	p.setRunning(true)
+// EngineInfo 实现core.EngineReporter接口
+func (p *dgramPinger) EngineInfo() (implementation, privilege string) {
+	return "Linux DGRAM Socket", "非特权模式"
+}
 
-	// 为每个目标启动一个goroutine
-	for _, target := range p.targets {
-		p.wg.Add(1)
-		go p.pingTarget(target)
-	}
+// openDgramSocket 创建IPv4 DGRAM ICMP套接字
+func openDgramSocket() (int, error) {
+	return syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM, syscall.IPPROTO_ICMP)
 }
 
 // pingTarget 对单个目标进行ping操作
-func (p *dgramPinger) pingTarget(target string) {
-	defer p.wg.Done()
+func (p *dgramPinger) pingTarget(target string, stop <-chan struct{}) {
+	// 解析目标地址（地址已在NewPinger中预验证，此处失败属于临时网络问题，退避重试直到成功）
+	dst, ok := p.waitResolved(target, stop)
+	if !ok {
+		return
+	}
 
-	// 解析目标地址（地址已在NewPinger中预验证，此处失败属于临时网络问题）
-	dst, err := net.ResolveIPAddr(p.config.GetIPProtocol(), target)
+	sock, err := openDgramSocket()
 	if err != nil {
-		// 发送错误结果，但不退出（可能是临时DNS问题）
-		p.sendPingResult(target, math.NaN())
+		p.reportError(target, fmt.Errorf("创建DGRAM套接字失败: %w", err))
 		return
 	}
+	defer syscall.Close(sock)
 
 	seq := 0
-	ticker := time.NewTicker(p.config.Interval)
+	// 获取该目标生效的间隔和超时（支持按目标覆盖）
+	interval, timeout := p.targetSettings(target)
+	tracker := p.newSeqTracker(timeout)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
+	resolveC, stopResolve := p.newResolveTicker()
+	defer stopResolve()
 
 	for {
 		select {
 		case <-p.stopChan:
 			return
+		case <-stop:
+			return
+		case <-resolveC:
+			if next, changed := p.lookupChanged(target, dst); changed {
+				dst = next
+				p.reportAddress(target, dst.String())
+			}
 		case <-ticker.C:
 			seq++
-			p.sendPing(dst, seq, target)
+			interval, timeout = p.refreshSettings(target, ticker, interval)
+			p.sendPing(sock, dst, seq, target, timeout, tracker)
 		}
 	}
 }
 
 // sendPing 发送单个ping包并等待回复
-func (p *dgramPinger) sendPing(dst *net.IPAddr, seq int, target string) {
+// 等待期间收到的其他序列号的回复会交给跟踪器分类，而不是被丢弃
+func (p *dgramPinger) sendPing(sock int, dst *net.IPAddr, seq int, target string, timeout time.Duration, tracker *seqTracker) {
 	// 构建ICMP消息
 	msg := &icmp.Message{
 		Type: ipv4.ICMPTypeEcho,
 		Code: 0,
 		Body: &icmp.Echo{
 			ID:   os.Getpid() & 0xffff,
-			Seq:  seq,
+			Seq:  wireSeq(seq),
 			Data: []byte("goping"),
 		},
 	}
@@ -104,34 +121,40 @@ func (p *dgramPinger) sendPing(dst *net.IPAddr, seq int, target string) {
 
 	// 记录发送时间
 	startTime := time.Now()
+	deadline := startTime.Add(timeout)
 
 	// 发送数据
-	err = syscall.Sendto(p.sock4, data, 0, sockaddr)
+	err = syscall.Sendto(sock, data, 0, sockaddr)
 	if err != nil {
+		p.reportError(target, fmt.Errorf("发送ICMP包失败: %w", err))
 		p.sendPingResult(target, math.NaN())
 		return
 	}
+	tracker.sent(seq, startTime)
 
-	// 设置接收超时
-	tv := syscall.Timeval{
-		Sec:  int64(p.config.Timeout.Seconds()),
-		Usec: int64(p.config.Timeout.Nanoseconds()/1000) % 1000000,
-	}
-
-	err = syscall.SetsockoptTimeval(p.sock4, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv)
-	if err != nil {
-		return
-	}
-
-	// 等待回复
+	// 等待回复，直到收到当前序列号的回复或超时
 	reply := make([]byte, 1500)
 	for {
-		n, from, err := syscall.Recvfrom(p.sock4, reply, 0)
+		// 按剩余时间设置接收超时
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			p.sendTimeout(target, seq, startTime, tracker)
+			return
+		}
+		tv := syscall.NsecToTimeval(remaining.Nanoseconds())
+		err = syscall.SetsockoptTimeval(sock, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv)
+		if err != nil {
+			p.reportError(target, fmt.Errorf("设置接收超时失败: %w", err))
+			return
+		}
+
+		n, from, err := syscall.Recvfrom(sock, reply, 0)
 		if err != nil {
 			// 超时或其他错误
-			p.sendPingResult(target, math.NaN())
+			p.sendTimeout(target, seq, startTime, tracker)
 			return
 		}
+		receiveTime := time.Now()
 
 		// 检查来源地址
 		if fromAddr, ok := from.(*syscall.SockaddrInet4); ok {
@@ -142,33 +165,14 @@ func (p *dgramPinger) sendPing(dst *net.IPAddr, seq int, target string) {
 		}
 
 		// 解析ICMP回复
-		replyMsg, err := icmp.ParseMessage(int(ipv4.ICMPTypeEchoReply), reply[:n])
-		if err != nil {
+		// DGRAM套接字的回显ID由内核改写并负责过滤，这里无需再校验ID
+		echo, ok := parseEchoReply(reply[:n], 4)
+		if !ok {
 			continue
 		}
 
-		// 验证回复的ID和序列号
-		if echo, ok := replyMsg.Body.(*icmp.Echo); ok {
-			if echo.ID == (os.Getpid()&0xffff) && echo.Seq == seq {
-				// 计算RTT
-				rtt := time.Since(startTime)
-
-				// 发送延迟结果（转换为毫秒）
-				latencyMs := float64(rtt.Nanoseconds()) / 1e6
-				p.sendPingResult(target, latencyMs)
-				return
-			}
+		if p.handleEchoReply(target, seq, echo.Seq, receiveTime, tracker) {
+			return
 		}
 	}
 }
-
-// Stop 停止Linux DGRAM模式的pinger
-func (p *dgramPinger) Stop() {
-	// 调用基础的停止方法
-	p.basePinger.Stop()
-
-	// 关闭socket
-	if p.sock4 > 0 {
-		syscall.Close(p.sock4)
-	}
-}
@@ -0,0 +1,124 @@
+// Package pinger 健康状态跟踪
+// 根据ping结果和探测错误维护每个目标的健康状态，并在状态变化时产生事件
+package pinger
+
+import (
+	"math"
+	"time"
+
+	"github.com/Kevin-Rudy/goping/pkg/core"
+)
+
+// healthDownThreshold 连续超时多少次后判定目标中断
+const healthDownThreshold = 3
+
+// targetHealthState 单个目标的健康状态
+type targetHealthState struct {
+	health          core.TargetHealth
+	consecutiveLoss int    // 连续超时次数
+	address         string // 目标当前使用的地址
+}
+
+// Health 实现core.DataSource接口，返回指定目标当前的健康状态
+func (bp *basePinger) Health(identifier string) core.TargetHealth {
+	bp.healthMu.Lock()
+	defer bp.healthMu.Unlock()
+
+	if state, exists := bp.health[identifier]; exists {
+		return state.health
+	}
+	return core.HealthUnknown
+}
+
+// Events 实现core.DataSource接口
+func (bp *basePinger) Events() <-chan core.SourceEvent {
+	return bp.eventChan
+}
+
+// updateHealth 根据ping结果更新目标健康状态
+func (bp *basePinger) updateHealth(result core.PingResult) {
+	// 重复和迟到的回复不反映目标当前状态
+	if result.Kind != core.ReplyNormal {
+		return
+	}
+
+	bp.healthMu.Lock()
+	state := bp.getHealthState(result.Identifier)
+	previous := state.health
+
+	if math.IsNaN(result.Latency) {
+		state.consecutiveLoss++
+		// 引擎故障期间的超时不改变故障状态，直到重新收到回复
+		if state.health != core.HealthError && state.consecutiveLoss >= healthDownThreshold {
+			state.health = core.HealthDown
+		}
+	} else {
+		state.consecutiveLoss = 0
+		state.health = core.HealthUp
+	}
+	current := state.health
+	bp.healthMu.Unlock()
+
+	if current != previous {
+		bp.sendEvent(core.SourceEvent{
+			Time:       time.Now(),
+			Identifier: result.Identifier,
+			Health:     current,
+		})
+	}
+}
+
+// reportError 上报探测引擎错误，目标进入故障状态
+func (bp *basePinger) reportError(target string, err error) {
+	bp.healthMu.Lock()
+	bp.getHealthState(target).health = core.HealthError
+	bp.healthMu.Unlock()
+
+	bp.sendEvent(core.SourceEvent{
+		Time:       time.Now(),
+		Identifier: target,
+		Health:     core.HealthError,
+		Err:        err,
+	})
+}
+
+// reportResolveFailure 上报目标地址解析失败，目标进入中断状态
+// 解析失败通常是DNS的临时问题，与探测引擎无关，不视为故障
+func (bp *basePinger) reportResolveFailure(target string, err error) {
+	bp.healthMu.Lock()
+	bp.getHealthState(target).health = core.HealthDown
+	bp.healthMu.Unlock()
+
+	bp.sendEvent(core.SourceEvent{
+		Time:       time.Now(),
+		Identifier: target,
+		Health:     core.HealthDown,
+		Err:        err,
+	})
+}
+
+// getHealthState 获取或创建目标的健康状态，调用方需持有healthMu
+func (bp *basePinger) getHealthState(target string) *targetHealthState {
+	state, exists := bp.health[target]
+	if !exists {
+		state = &targetHealthState{health: core.HealthUnknown}
+		bp.health[target] = state
+	}
+	return state
+}
+
+// sendEvent 发送事件到事件通道，通道满时丢弃
+func (bp *basePinger) sendEvent(event core.SourceEvent) {
+	if !bp.isRunning() {
+		return
+	}
+
+	select {
+	case bp.eventChan <- event:
+		// 成功发送
+	case <-bp.stopChan:
+		// 停止信号，不再发送
+	default:
+		// 通道满了，丢弃这个事件，健康状态仍可通过Health()查询
+	}
+}
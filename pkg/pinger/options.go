@@ -38,6 +38,27 @@ func WithBufferSize(size int) Option {
 	}
 }
 
+// WithLateGrace 设置迟到回复的宽限时间
+func WithLateGrace(grace time.Duration) Option {
+	return func(c *Config) {
+		c.LateGrace = grace
+	}
+}
+
+// WithResolveInterval 设置重新解析目标域名的间隔，0表示只在启动时解析
+func WithResolveInterval(interval time.Duration) Option {
+	return func(c *Config) {
+		c.ResolveInterval = interval
+	}
+}
+
+// WithTargetOverride 设置单个目标的ping间隔和超时时间，零值表示沿用全局配置
+func WithTargetOverride(target string, interval, timeout time.Duration) Option {
+	return func(c *Config) {
+		c.SetTargetOverride(target, interval, timeout)
+	}
+}
+
 // NewPingerWithOptions 使用选项模式创建Pinger
 func NewPingerWithOptions(targets []string, opts ...Option) (core.DataSource, error) {
 	config := DefaultConfig()
@@ -3,9 +3,14 @@
 package pinger
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"math"
+	"net"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Kevin-Rudy/goping/pkg/core"
@@ -13,22 +18,46 @@ import (
 
 // basePinger 定义了所有pinger实现的基本结构
 type basePinger struct {
-	targets   []string             // ping目标列表
-	config    *Config              // 配置信息（替代原来的全局变量）
-	dataChan  chan core.PingResult // 数据输出通道
-	stopChan  chan struct{}        // 停止信号通道
-	wg        sync.WaitGroup       // 等待组，用于优雅关闭
-	running   bool                 // 运行状态
-	runningMu sync.RWMutex         // 保护running状态的锁
+	targets    []string                                           // ping目标列表
+	targetRuns map[string]*targetRun                              // 每个目标的ping循环，用于运行时移除目标
+	targetsMu  sync.Mutex                                         // 保护targets和targetRuns的锁
+	runTarget  func(target string, stop <-chan struct{})          // 具体实现提供的单目标ping循环
+	lookup     func(network, address string) (*net.IPAddr, error) // 解析目标地址，测试时可替换
+	config     *Config                                            // 配置信息（创建时复制，运行时调整不影响调用方的配置）
+	configMu   sync.RWMutex                                       // 保护运行时可调整的配置项（探测间隔）
+	dataChan   chan core.PingResult                               // 数据输出通道
+	eventChan  chan core.SourceEvent                              // 事件输出通道
+	dropped    atomic.Uint64                                      // 因数据通道已满而丢弃的结果数
+	health     map[string]*targetHealthState                      // 每个目标的健康状态
+	healthMu   sync.Mutex                                         // 保护health的锁
+	stopChan   chan struct{}                                      // 停止信号通道
+	wg         sync.WaitGroup                                     // 等待组，用于优雅关闭
+	running    bool                                               // 运行状态
+	runningMu  sync.RWMutex                                       // 保护running状态的锁
+}
+
+// targetRun 单个目标正在运行的ping循环
+type targetRun struct {
+	stop chan struct{} // 关闭时通知ping循环退出
+	done chan struct{} // ping循环退出后关闭
+}
+
+// newSeqTracker 按配置创建单个目标的序列号跟踪器
+func (bp *basePinger) newSeqTracker(timeout time.Duration) *seqTracker {
+	return newSeqTracker(defaultSeqWindow, timeout+bp.config.LateGrace)
 }
 
 // newBasePinger 创建基础pinger结构
 func newBasePinger(targets []string, config *Config) *basePinger {
 	return &basePinger{
-		targets:  targets,
-		config:   config,
-		dataChan: make(chan core.PingResult, config.BufferSize), // 使用配置的缓冲区大小
-		stopChan: make(chan struct{}),
+		targets:    append([]string(nil), targets...),
+		targetRuns: make(map[string]*targetRun),
+		lookup:     net.ResolveIPAddr,
+		config:     config.Clone(),
+		dataChan:   make(chan core.PingResult, config.BufferSize), // 使用配置的缓冲区大小
+		eventChan:  make(chan core.SourceEvent, config.BufferSize),
+		health:     make(map[string]*targetHealthState),
+		stopChan:   make(chan struct{}),
 	}
 }
 
@@ -37,6 +66,158 @@ func (bp *basePinger) DataStream() <-chan core.PingResult {
 	return bp.dataChan
 }
 
+// Start 实现core.DataSource接口，为每个目标启动一个goroutine
+// ctx被取消时自动调用Stop
+func (bp *basePinger) Start(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	bp.targetsMu.Lock()
+	defer bp.targetsMu.Unlock()
+
+	select {
+	case <-bp.stopChan:
+		return errors.New("pinger已停止，无法重新启动")
+	default:
+	}
+	if bp.isRunning() {
+		return errors.New("pinger已在运行")
+	}
+
+	bp.setRunning(true)
+	for _, target := range bp.targets {
+		bp.launchTarget(target)
+	}
+
+	// 监听ctx，取消时停止pinger
+	go func() {
+		select {
+		case <-ctx.Done():
+			bp.Stop()
+		case <-bp.stopChan:
+		}
+	}()
+
+	return nil
+}
+
+// launchTarget 启动单个目标的ping循环，调用方需持有targetsMu
+func (bp *basePinger) launchTarget(target string) {
+	run := &targetRun{stop: make(chan struct{}), done: make(chan struct{})}
+	bp.targetRuns[target] = run
+
+	bp.wg.Add(1)
+	go func() {
+		defer bp.wg.Done()
+		defer close(run.done)
+		bp.runTarget(target, run.stop)
+	}()
+}
+
+// AddTarget 实现core.TargetManager接口，在运行时添加新目标
+func (bp *basePinger) AddTarget(target string) error {
+	if err := bp.config.ValidateTargets([]string{target}); err != nil {
+		return err
+	}
+
+	bp.targetsMu.Lock()
+	defer bp.targetsMu.Unlock()
+
+	for _, existing := range bp.targets {
+		if existing == target {
+			return fmt.Errorf("目标 '%s' 已存在", target)
+		}
+	}
+	bp.targets = append(bp.targets, target)
+
+	if bp.isRunning() {
+		bp.launchTarget(target)
+	}
+	return nil
+}
+
+// RemoveTarget 实现core.TargetManager接口，在运行时移除目标并停止对其的ping
+// 等待该目标的ping循环退出后才返回（最长约为一个超时时间），返回后不会再产生该目标此前探测的结果，
+// 消费方可以据此区分同名目标重新添加前后的结果
+func (bp *basePinger) RemoveTarget(target string) error {
+	bp.targetsMu.Lock()
+	defer bp.targetsMu.Unlock()
+
+	index := -1
+	for i, existing := range bp.targets {
+		if existing == target {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return fmt.Errorf("目标 '%s' 不存在", target)
+	}
+	bp.targets = append(bp.targets[:index], bp.targets[index+1:]...)
+
+	if run, exists := bp.targetRuns[target]; exists {
+		close(run.stop)
+		delete(bp.targetRuns, target)
+		// 持有targetsMu等待，避免同名目标在旧的ping循环退出前被重新添加
+		<-run.done
+	}
+
+	bp.healthMu.Lock()
+	delete(bp.health, target)
+	bp.healthMu.Unlock()
+	return nil
+}
+
+// SetInterval 实现core.IntervalSetter接口，在运行时调整探测间隔
+// target为空时修改全局间隔，单独设置过间隔的目标不受影响
+func (bp *basePinger) SetInterval(target string, interval time.Duration) error {
+	if target != "" {
+		bp.targetsMu.Lock()
+		exists := false
+		for _, existing := range bp.targets {
+			if existing == target {
+				exists = true
+				break
+			}
+		}
+		bp.targetsMu.Unlock()
+		if !exists {
+			return fmt.Errorf("目标 '%s' 不存在", target)
+		}
+	}
+
+	bp.configMu.Lock()
+	defer bp.configMu.Unlock()
+
+	_, timeout := bp.config.TargetSettings(target)
+	if err := validateProbeSettings(interval, timeout); err != nil {
+		return err
+	}
+	if target == "" {
+		bp.config.Interval = interval
+	} else {
+		bp.config.SetTargetOverride(target, interval, 0)
+	}
+	return nil
+}
+
+// targetSettings 返回目标当前生效的ping间隔和超时时间
+func (bp *basePinger) targetSettings(target string) (interval, timeout time.Duration) {
+	bp.configMu.RLock()
+	defer bp.configMu.RUnlock()
+	return bp.config.TargetSettings(target)
+}
+
+// refreshSettings 重新读取目标的ping间隔和超时，间隔被调整时重置定时器
+func (bp *basePinger) refreshSettings(target string, ticker *time.Ticker, interval time.Duration) (time.Duration, time.Duration) {
+	next, timeout := bp.targetSettings(target)
+	if next != interval {
+		ticker.Reset(next)
+	}
+	return next, timeout
+}
+
 // Stop 实现core.DataSource接口
 func (bp *basePinger) Stop() {
 	bp.runningMu.Lock()
@@ -47,14 +228,17 @@ func (bp *basePinger) Stop() {
 	bp.running = false
 	bp.runningMu.Unlock()
 
-	// 发送停止信号
+	// 发送停止信号（持有targetsMu，避免与AddTarget并发启动新的goroutine）
+	bp.targetsMu.Lock()
 	close(bp.stopChan)
+	bp.targetsMu.Unlock()
 
 	// 等待所有goroutine结束
 	bp.wg.Wait()
 
-	// 关闭数据通道
+	// 关闭数据通道和事件通道
 	close(bp.dataChan)
+	close(bp.eventChan)
 }
 
 // isRunning 检查是否正在运行
@@ -78,16 +262,20 @@ func (bp *basePinger) sendPingResult(target string, latency float64) {
 
 // sendPingResultWithTime 发送带时间戳的ping结果到数据通道
 func (bp *basePinger) sendPingResultWithTime(target string, latency float64, sendTime, receiveTime time.Time) {
-	if !bp.isRunning() {
-		return
-	}
-
-	result := core.PingResult{
+	bp.sendResult(core.PingResult{
 		Identifier:  target,
 		Latency:     latency,
 		SendTime:    sendTime,
 		ReceiveTime: receiveTime,
+	})
+}
+
+// sendResult 将完整的ping结果发送到数据通道，并更新目标健康状态
+func (bp *basePinger) sendResult(result core.PingResult) {
+	if !bp.isRunning() {
+		return
 	}
+	bp.updateHealth(result)
 
 	select {
 	case bp.dataChan <- result:
@@ -97,8 +285,49 @@ func (bp *basePinger) sendPingResultWithTime(target string, latency float64, sen
 		return
 	default:
 		// 通道满了，丢弃这个数据点
-		// 在高频ping场景中这是可以接受的
+		// 在高频ping场景中这是可以接受的，丢弃的数量通过DroppedResults报告给消费方
+		bp.dropped.Add(1)
+	}
+}
+
+// DroppedResults 实现core.DropCounter接口，返回因数据通道已满而丢弃的结果数
+func (bp *basePinger) DroppedResults() uint64 {
+	return bp.dropped.Load()
+}
+
+// sendTimeout 发送超时结果，并在跟踪器中将该探测包标记为超时
+func (bp *basePinger) sendTimeout(target string, seq int, sendTime time.Time, tracker *seqTracker) {
+	tracker.expire(seq)
+	bp.sendResult(core.PingResult{
+		Identifier: target,
+		Latency:    math.NaN(),
+		SendTime:   sendTime,
+		Seq:        seq,
+	})
+}
+
+// handleEchoReply 对收到的回复进行分类并发送结果
+// 重复、迟到等回复同样会被发送，由消费方计入异常统计，迟到回复携带真实的往返延迟；
+// 返回值表示该回复是否对应当前正在等待的探测包
+func (bp *basePinger) handleEchoReply(target string, currentSeq, seqOnWire int, receiveTime time.Time, tracker *seqTracker) bool {
+	kind, outOfOrder, seq, sendTime, ok := tracker.classify(seqOnWire, receiveTime)
+	if !ok {
+		// 不在跟踪窗口内的回复，无法计算延迟，直接忽略
+		return false
 	}
+
+	latencyMs := float64(receiveTime.Sub(sendTime).Nanoseconds()) / 1e6
+	bp.sendResult(core.PingResult{
+		Identifier:  target,
+		Latency:     latencyMs,
+		SendTime:    sendTime,
+		ReceiveTime: receiveTime,
+		Seq:         seq,
+		Kind:        kind,
+		OutOfOrder:  outOfOrder,
+	})
+
+	return kind == core.ReplyNormal && seq == currentSeq
 }
 
 // NewPinger 创建新的Pinger实例
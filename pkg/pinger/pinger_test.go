@@ -1,7 +1,13 @@
 package pinger
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"math"
+	"net"
+	"runtime"
+	"sync"
 	"testing"
 	"time"
 
@@ -429,3 +435,517 @@ func TestNewPingerWithOptions(t *testing.T) {
 
 	t.Log("NewPingerWithOptions test completed")
 }
+
+// TestSeqTrackerClassify 测试序列号跟踪器对重复、乱序和迟到回复的分类
+func TestSeqTrackerClassify(t *testing.T) {
+	tracker := newSeqTracker(defaultSeqWindow, 10*time.Second)
+	base := time.Now()
+
+	for seq := 1; seq <= 3; seq++ {
+		tracker.sent(seq, base.Add(time.Duration(seq)*time.Second))
+	}
+
+	// 第1个包超时
+	tracker.expire(1)
+
+	// 正常回复
+	kind, outOfOrder, seq, sendTime, ok := tracker.classify(3, base)
+	if !ok || kind != core.ReplyNormal || outOfOrder || seq != 3 {
+		t.Errorf("Expected normal reply for seq 3, got kind=%v outOfOrder=%v seq=%d ok=%v", kind, outOfOrder, seq, ok)
+	}
+	if !sendTime.Equal(base.Add(3 * time.Second)) {
+		t.Errorf("Expected send time of seq 3, got %v", sendTime)
+	}
+
+	// 重复回复
+	kind, _, _, _, ok = tracker.classify(3, base)
+	if !ok || kind != core.ReplyDuplicate {
+		t.Errorf("Expected duplicate reply for seq 3, got kind=%v ok=%v", kind, ok)
+	}
+
+	// 乱序回复：在seq 3之后到达
+	kind, outOfOrder, _, _, ok = tracker.classify(2, base)
+	if !ok || kind != core.ReplyNormal || !outOfOrder {
+		t.Errorf("Expected out-of-order reply for seq 2, got kind=%v outOfOrder=%v ok=%v", kind, outOfOrder, ok)
+	}
+
+	// 迟到回复：对应的包已超时
+	kind, _, _, _, ok = tracker.classify(1, base)
+	if !ok || kind != core.ReplyLate {
+		t.Errorf("Expected late reply for seq 1, got kind=%v ok=%v", kind, ok)
+	}
+
+	// 未知序列号
+	if _, _, _, _, ok = tracker.classify(99, base); ok {
+		t.Error("Expected unknown seq to be ignored")
+	}
+}
+
+// TestSeqTrackerWindow 测试跟踪窗口外的旧记录会被清理，且支持16位序列号回绕
+func TestSeqTrackerWindow(t *testing.T) {
+	tracker := newSeqTracker(4, 10*time.Second)
+	now := time.Now()
+
+	for seq := 1; seq <= 10; seq++ {
+		tracker.sent(seq, now)
+	}
+	if _, _, _, _, ok := tracker.classify(2, now); ok {
+		t.Error("Expected seq outside window to be forgotten")
+	}
+
+	tracker.sent(65537, now)
+	_, _, seq, _, ok := tracker.classify(wireSeq(65537), now)
+	if !ok || seq != 65537 {
+		t.Errorf("Expected wrapped seq to map back to 65537, got seq=%d ok=%v", seq, ok)
+	}
+}
+
+// TestSeqTrackerLateGrace 测试超过宽限期的迟到回复会被忽略
+func TestSeqTrackerLateGrace(t *testing.T) {
+	tracker := newSeqTracker(defaultSeqWindow, 5*time.Second)
+	base := time.Now()
+
+	tracker.sent(1, base)
+	tracker.sent(2, base)
+	tracker.expire(1)
+	tracker.expire(2)
+
+	kind, _, _, sendTime, ok := tracker.classify(1, base.Add(4*time.Second))
+	if !ok || kind != core.ReplyLate {
+		t.Errorf("Expected late reply within grace window, got kind=%v ok=%v", kind, ok)
+	}
+	if latency := base.Add(4 * time.Second).Sub(sendTime); latency != 4*time.Second {
+		t.Errorf("Expected real latency of 4s for late reply, got %v", latency)
+	}
+
+	if _, _, _, _, ok := tracker.classify(2, base.Add(6*time.Second)); ok {
+		t.Error("Expected reply after grace window to be ignored")
+	}
+}
+
+// TestAddRemoveTarget 测试运行时增删目标
+func TestAddRemoveTarget(t *testing.T) {
+	config := DefaultConfig()
+	bp := newBasePinger([]string{"127.0.0.1"}, config)
+
+	var _ core.TargetManager = bp
+
+	started := make(chan string, 4)
+	stopped := make(chan string, 4)
+	bp.runTarget = func(target string, stop <-chan struct{}) {
+		started <- target
+		select {
+		case <-stop:
+			// 模拟退出前还有未完成的探测
+			time.Sleep(50 * time.Millisecond)
+		case <-bp.stopChan:
+		}
+		stopped <- target
+	}
+
+	if err := bp.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if target := <-started; target != "127.0.0.1" {
+		t.Errorf("Expected initial target to start, got %s", target)
+	}
+
+	if err := bp.AddTarget("127.0.0.2"); err != nil {
+		t.Fatalf("AddTarget failed: %v", err)
+	}
+	if target := <-started; target != "127.0.0.2" {
+		t.Errorf("Expected added target to start, got %s", target)
+	}
+
+	if err := bp.AddTarget("127.0.0.2"); err == nil {
+		t.Error("Expected error when adding duplicate target")
+	}
+	if err := bp.AddTarget(""); err == nil {
+		t.Error("Expected error when adding empty target")
+	}
+
+	if err := bp.RemoveTarget("127.0.0.1"); err != nil {
+		t.Fatalf("RemoveTarget failed: %v", err)
+	}
+	// RemoveTarget返回时旧的ping循环应已退出
+	select {
+	case target := <-stopped:
+		if target != "127.0.0.1" {
+			t.Errorf("Expected removed target to stop, got %s", target)
+		}
+	default:
+		t.Fatal("RemoveTarget returned before the target goroutine stopped")
+	}
+
+	if err := bp.RemoveTarget("127.0.0.1"); err == nil {
+		t.Error("Expected error when removing unknown target")
+	}
+	if len(bp.targets) != 1 || bp.targets[0] != "127.0.0.2" {
+		t.Errorf("Unexpected targets after removal: %v", bp.targets)
+	}
+
+	bp.Stop()
+}
+
+// TestStartContext 测试Start的错误返回以及ctx取消后自动停止
+func TestStartContext(t *testing.T) {
+	bp := newBasePinger([]string{"127.0.0.1"}, DefaultConfig())
+	bp.runTarget = func(target string, stop <-chan struct{}) {
+		<-bp.stopChan
+	}
+
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if err := bp.Start(cancelled); err == nil {
+		t.Error("Expected error when starting with a cancelled context")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := bp.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := bp.Start(ctx); err == nil {
+		t.Error("Expected error when starting twice")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-bp.DataStream():
+		if ok {
+			t.Error("Expected data channel to be closed after context cancellation")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Pinger did not stop after context cancellation")
+	}
+	if _, ok := <-bp.Events(); ok {
+		t.Error("Expected event channel to be closed after stop")
+	}
+
+	if err := bp.Start(context.Background()); err == nil {
+		t.Error("Expected error when restarting a stopped pinger")
+	}
+}
+
+// TestHealthTransitions 测试健康状态变化及事件上报
+func TestHealthTransitions(t *testing.T) {
+	bp := newBasePinger([]string{"test.com"}, DefaultConfig())
+	bp.setRunning(true)
+
+	if health := bp.Health("test.com"); health != core.HealthUnknown {
+		t.Errorf("Expected unknown health initially, got %v", health)
+	}
+
+	bp.sendPingResult("test.com", 10)
+	for i := 0; i < healthDownThreshold; i++ {
+		bp.sendPingResult("test.com", math.NaN())
+	}
+	if health := bp.Health("test.com"); health != core.HealthDown {
+		t.Errorf("Expected down after consecutive timeouts, got %v", health)
+	}
+
+	bp.reportError("test.com", errors.New("socket error"))
+	bp.sendPingResult("test.com", math.NaN())
+	if health := bp.Health("test.com"); health != core.HealthError {
+		t.Errorf("Timeouts should not clear engine error, got %v", health)
+	}
+
+	bp.sendPingResult("test.com", 12)
+
+	expected := []core.TargetHealth{core.HealthUp, core.HealthDown, core.HealthError, core.HealthUp}
+	for i, health := range expected {
+		select {
+		case event := <-bp.Events():
+			if event.Identifier != "test.com" || event.Health != health {
+				t.Errorf("Event %d: expected %v, got %+v", i, health, event)
+			}
+			if health == core.HealthError && event.Err == nil {
+				t.Error("Expected error event to carry the error")
+			}
+		default:
+			t.Fatalf("Expected event %d (%v), got none", i, health)
+		}
+	}
+
+	bp.Stop()
+}
+
+// TestTargetOverrides 测试按目标覆盖间隔和超时
+func TestTargetOverrides(t *testing.T) {
+	config := DefaultConfig()
+	config.SetTargetOverride("gateway", 100*time.Millisecond, 0)
+	config.SetTargetOverride("wan.host", 5*time.Second, 10*time.Second)
+
+	interval, timeout := config.TargetSettings("gateway")
+	if interval != 100*time.Millisecond || timeout != config.Timeout {
+		t.Errorf("Unexpected gateway settings: interval=%v timeout=%v", interval, timeout)
+	}
+
+	interval, timeout = config.TargetSettings("wan.host")
+	if interval != 5*time.Second || timeout != 10*time.Second {
+		t.Errorf("Unexpected wan.host settings: interval=%v timeout=%v", interval, timeout)
+	}
+
+	interval, timeout = config.TargetSettings("other")
+	if interval != config.Interval || timeout != config.Timeout {
+		t.Errorf("Targets without override should use global settings, got interval=%v timeout=%v", interval, timeout)
+	}
+
+	if err := config.Validate(); err != nil {
+		t.Errorf("Valid overrides should pass validation: %v", err)
+	}
+
+	config.SetTargetOverride("bad", 5*time.Millisecond, 0)
+	if err := config.Validate(); err == nil {
+		t.Error("Expected error for override interval below 10ms")
+	}
+
+	optionConfig := DefaultConfig()
+	WithTargetOverride("gateway", 0, 500*time.Millisecond)(optionConfig)
+	if _, timeout := optionConfig.TargetSettings("gateway"); timeout != 500*time.Millisecond {
+		t.Errorf("Expected option to set timeout override, got %v", timeout)
+	}
+}
+
+// TestReportAddress 测试解析地址变化事件
+func TestReportAddress(t *testing.T) {
+	bp := newBasePinger([]string{"test.com"}, DefaultConfig())
+	bp.setRunning(true)
+
+	bp.reportAddress("test.com", "192.0.2.1")
+	bp.reportAddress("test.com", "192.0.2.1")
+	bp.reportAddress("test.com", "192.0.2.2")
+
+	for _, address := range []string{"192.0.2.1", "192.0.2.2"} {
+		select {
+		case event := <-bp.Events():
+			if event.Identifier != "test.com" || event.Address != address || event.Err != nil {
+				t.Errorf("Expected address event for %s, got %+v", address, event)
+			}
+		default:
+			t.Fatalf("Expected address event for %s, got none", address)
+		}
+	}
+	select {
+	case event := <-bp.Events():
+		t.Errorf("Unchanged address should not produce an event, got %+v", event)
+	default:
+	}
+
+	dst, err := bp.resolveTarget("127.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to resolve 127.0.0.1: %v", err)
+	}
+	if _, changed := bp.lookupChanged("127.0.0.1", dst); changed {
+		t.Error("Re-resolving an IP literal should not report a change")
+	}
+
+	config := DefaultConfig()
+	config.ResolveInterval = -time.Second
+	if err := config.Validate(); err == nil {
+		t.Error("Expected error for negative resolve interval")
+	}
+
+	bp.Stop()
+}
+
+// TestResolveRetry 测试地址解析失败时退避重试，期间目标视为中断而不是故障
+func TestResolveRetry(t *testing.T) {
+	config := DefaultConfig()
+	config.Interval = 10 * time.Millisecond
+	bp := newBasePinger([]string{"test.com", "missing.com"}, config)
+
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	bp.lookup = func(network, address string) (*net.IPAddr, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[address]++
+		if address == "missing.com" || attempts[address] <= 2 {
+			return nil, errors.New("no such host")
+		}
+		return &net.IPAddr{IP: net.ParseIP("192.0.2.1")}, nil
+	}
+
+	resolved := make(chan string, 1)
+	bp.runTarget = func(target string, stop <-chan struct{}) {
+		if dst, ok := bp.waitResolved(target, stop); ok {
+			resolved <- dst.String()
+		}
+	}
+	if err := bp.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	select {
+	case address := <-resolved:
+		if address != "192.0.2.1" {
+			t.Errorf("Expected resolved address 192.0.2.1, got %s", address)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Target was not resolved after retries")
+	}
+
+	failures := 0
+	for len(bp.Events()) > 0 {
+		event := <-bp.Events()
+		if event.Identifier != "test.com" || event.Err == nil {
+			continue
+		}
+		failures++
+		if event.Health != core.HealthDown {
+			t.Errorf("Expected resolve failure to report HealthDown, got %v", event.Health)
+		}
+	}
+	if failures != 2 {
+		t.Errorf("Expected 2 resolve failure events, got %d", failures)
+	}
+	if health := bp.Health("missing.com"); health != core.HealthDown {
+		t.Errorf("Expected unresolvable target to be down, got %v", health)
+	}
+
+	// 仍在重试的目标可以被移除
+	removed := make(chan error, 1)
+	go func() { removed <- bp.RemoveTarget("missing.com") }()
+	select {
+	case err := <-removed:
+		if err != nil {
+			t.Errorf("RemoveTarget failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("RemoveTarget blocked while target was retrying resolution")
+	}
+
+	bp.Stop()
+}
+
+// TestSetInterval 测试运行时调整探测间隔
+func TestSetInterval(t *testing.T) {
+	config := DefaultConfig()
+	config.SetTargetOverride("gateway", 100*time.Millisecond, 0)
+	bp := newBasePinger([]string{"test.com", "gateway"}, config)
+
+	var setter core.IntervalSetter = bp
+	if err := setter.SetInterval("", time.Second); err != nil {
+		t.Fatalf("Failed to set global interval: %v", err)
+	}
+	if interval, _ := bp.targetSettings("test.com"); interval != time.Second {
+		t.Errorf("Expected global interval 1s, got %v", interval)
+	}
+	if interval, _ := bp.targetSettings("gateway"); interval != 100*time.Millisecond {
+		t.Errorf("Targets with their own interval should keep it, got %v", interval)
+	}
+	if config.Interval != DefaultConfig().Interval {
+		t.Error("Runtime changes should not modify the caller's config")
+	}
+
+	if err := bp.SetInterval("gateway", 500*time.Millisecond); err != nil {
+		t.Fatalf("Failed to set target interval: %v", err)
+	}
+	if interval, _ := bp.targetSettings("gateway"); interval != 500*time.Millisecond {
+		t.Errorf("Expected gateway interval 500ms, got %v", interval)
+	}
+
+	if err := bp.SetInterval("unknown", time.Second); err == nil {
+		t.Error("Expected error for unknown target")
+	}
+	if err := bp.SetInterval("", time.Millisecond); err == nil {
+		t.Error("Expected error for interval below 10ms")
+	}
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	if interval, _ := bp.refreshSettings("test.com", ticker, time.Hour); interval != time.Second {
+		t.Errorf("Expected refreshed interval 1s, got %v", interval)
+	}
+}
+
+// TestDroppedResults 测试数据通道已满时丢弃的结果被计数
+func TestDroppedResults(t *testing.T) {
+	config := DefaultConfig()
+	config.BufferSize = 2
+	bp := newBasePinger([]string{"test.com"}, config)
+	bp.setRunning(true)
+
+	var counter core.DropCounter = bp
+	for i := 0; i < 5; i++ {
+		bp.sendPingResult("test.com", 1.0)
+	}
+	if dropped := counter.DroppedResults(); dropped != 3 {
+		t.Errorf("Expected 3 dropped results with a buffer of 2, got %d", dropped)
+	}
+
+	<-bp.DataStream()
+	bp.sendPingResult("test.com", 1.0)
+	if dropped := counter.DroppedResults(); dropped != 3 {
+		t.Errorf("Results sent after the channel drains should not be dropped, got %d", dropped)
+	}
+}
+
+// TestEngineInfo 测试数据源报告实际使用的探测实现方式和权限模式
+func TestEngineInfo(t *testing.T) {
+	source, err := NewPinger([]string{"127.0.0.1"}, DefaultConfig())
+	if err != nil {
+		t.Skipf("当前环境无法创建pinger: %v", err)
+	}
+	reporter, ok := source.(core.EngineReporter)
+	if !ok {
+		t.Fatal("Expected pinger to implement core.EngineReporter")
+	}
+	implementation, privilege := reporter.EngineInfo()
+	if implementation == "" {
+		t.Error("Expected a non-empty implementation")
+	}
+	if wantPrivileged := HasPrivilegedAccess(); (privilege == "特权模式") != wantPrivileged {
+		t.Errorf("Privilege mode %q does not match privileged access %v", privilege, wantPrivileged)
+	}
+}
+
+// TestUnprivilegedMultipleTargets 测试非特权模式下多个目标同时探测时，每个目标都收到自己的回复
+// 每个目标使用独立的套接字，回复不会被其他目标的接收循环读走；不允许创建DGRAM ICMP套接字的环境跳过
+func TestUnprivilegedMultipleTargets(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("DGRAM ICMP套接字仅在Linux上使用")
+	}
+	config := DefaultConfig()
+	config.Interval = 10 * time.Millisecond
+	config.Timeout = 200 * time.Millisecond
+	var targets []string
+	for i := 1; i <= 20; i++ {
+		targets = append(targets, fmt.Sprintf("127.0.0.%d", i))
+	}
+	source, err := getPlatformCapability().createUnprivilegedPinger(targets, config)
+	if err != nil {
+		t.Skipf("当前环境不允许创建DGRAM ICMP套接字: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := source.Start(ctx); err != nil {
+		t.Fatalf("Failed to start pinger: %v", err)
+	}
+	received := make(map[string]int)
+	deadline := time.After(5 * time.Second)
+collect:
+	for {
+		select {
+		case result := <-source.DataStream():
+			if math.IsNaN(result.Latency) {
+				t.Errorf("Unexpected timeout for %s", result.Identifier)
+			} else if result.Kind != core.ReplyNormal {
+				t.Errorf("Unexpected %v reply for %s", result.Kind, result.Identifier)
+			}
+			received[result.Identifier]++
+			done := true
+			for _, target := range targets {
+				done = done && received[target] >= 50
+			}
+			if done {
+				break collect
+			}
+		case <-deadline:
+			t.Errorf("Expected replies for every target, got %v", received)
+			break collect
+		}
+	}
+	cancel()
+	source.Stop()
+}
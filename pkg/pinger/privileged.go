@@ -3,6 +3,7 @@
 package pinger
 
 import (
+	"fmt"
 	"math"
 	"net"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"github.com/Kevin-Rudy/goping/pkg/core"
 	"golang.org/x/net/icmp"
 	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
 )
 
 // privilegedPinger 特权模式的ping实现
@@ -23,29 +25,20 @@ func newPrivilegedPinger(targets []string, config *Config) (core.DataSource, err
 	p := &privilegedPinger{
 		basePinger: newBasePinger(targets, config),
 	}
+	p.runTarget = p.pingTarget
 	return p, nil
 }
 
-// Start 实现core.DataSource接口，启动ping操作
-func (p *privilegedPinger) Start() {
-	p.setRunning(true)
-
-	// 为每个目标启动一个goroutine
-	for _, target := range p.targets {
-		p.wg.Add(1)
-		go p.pingTarget(target)
-	}
+// EngineInfo 实现core.EngineReporter接口
+func (p *privilegedPinger) EngineInfo() (implementation, privilege string) {
+	return GetOSName() + " Raw Socket", "特权模式"
 }
 
 // pingTarget 对单个目标进行ping操作
-func (p *privilegedPinger) pingTarget(target string) {
-	defer p.wg.Done()
-
-	// 解析目标地址（地址已在NewPinger中预验证，此处失败属于临时网络问题）
-	dst, err := net.ResolveIPAddr(p.config.GetIPProtocol(), target)
-	if err != nil {
-		// 发送错误结果，但不退出（可能是临时DNS问题）
-		p.sendPingResult(target, math.NaN())
+func (p *privilegedPinger) pingTarget(target string, stop <-chan struct{}) {
+	// 解析目标地址（地址已在NewPinger中预验证，此处失败属于临时网络问题，退避重试直到成功）
+	dst, ok := p.waitResolved(target, stop)
+	if !ok {
 		return
 	}
 
@@ -56,37 +49,61 @@ func (p *privilegedPinger) pingTarget(target string) {
 	}
 	conn, err := net.Dial(protocol, dst.String())
 	if err != nil {
-		// 连接失败，发送错误结果
-		p.sendPingResult(target, math.NaN())
+		p.reportError(target, fmt.Errorf("创建原始套接字失败: %w", err))
 		return
 	}
-	defer conn.Close()
+	defer func() {
+		conn.Close()
+	}()
+
+	// 获取该目标生效的间隔和超时（支持按目标覆盖）
+	interval, timeout := p.targetSettings(target)
 
-	ticker := time.NewTicker(p.config.Interval)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
+	resolveC, stopResolve := p.newResolveTicker()
+	defer stopResolve()
 
 	seq := 0
+	tracker := p.newSeqTracker(timeout)
 
 	for {
 		select {
 		case <-p.stopChan:
 			return
+		case <-stop:
+			return
+		case <-resolveC:
+			// 地址变化时切换到新地址，新套接字创建失败则继续使用原地址
+			next, changed := p.lookupChanged(target, dst)
+			if !changed {
+				continue
+			}
+			nextConn, err := net.Dial(protocol, next.String())
+			if err != nil {
+				continue
+			}
+			conn.Close()
+			conn, dst = nextConn, next
+			p.reportAddress(target, dst.String())
 		case <-ticker.C:
 			seq++
-			p.sendPing(conn, target, seq)
+			interval, timeout = p.refreshSettings(target, ticker, interval)
+			p.sendPing(conn, target, seq, timeout, tracker)
 		}
 	}
 }
 
 // sendPing 发送单个ping包
-func (p *privilegedPinger) sendPing(conn net.Conn, target string, seq int) {
+// 等待期间收到的其他序列号的回复会交给跟踪器分类，而不是被丢弃
+func (p *privilegedPinger) sendPing(conn net.Conn, target string, seq int, timeout time.Duration, tracker *seqTracker) {
 	// 创建ICMP包
 	icmpPacket := &icmp.Message{
 		Type: ipv4.ICMPTypeEcho,
 		Code: 0,
 		Body: &icmp.Echo{
 			ID:   os.Getpid() & 0xffff,
-			Seq:  seq,
+			Seq:  wireSeq(seq),
 			Data: []byte("goping"),
 		},
 	}
@@ -102,45 +119,61 @@ func (p *privilegedPinger) sendPing(conn net.Conn, target string, seq int) {
 	startTime := time.Now()
 
 	// 设置超时
-	conn.SetDeadline(time.Now().Add(p.config.Timeout))
+	conn.SetDeadline(startTime.Add(timeout))
 
 	// 发送ICMP包
 	_, err = conn.Write(data)
 	if err != nil {
+		p.reportError(target, fmt.Errorf("发送ICMP包失败: %w", err))
 		p.sendPingResult(target, math.NaN())
 		return
 	}
+	tracker.sent(seq, startTime)
 
-	// 读取回复
+	// 读取回复，直到收到当前序列号的回复或超时
 	reply := make([]byte, 1500)
-	n, err := conn.Read(reply)
-	if err != nil {
-		p.sendPingResult(target, math.NaN())
-		return
-	}
+	for {
+		n, err := conn.Read(reply)
+		if err != nil {
+			p.sendTimeout(target, seq, startTime, tracker)
+			return
+		}
+		receiveTime := time.Now()
 
-	// 计算往返时间
-	rtt := time.Since(startTime)
+		// 解析ICMP回复，并验证这是我们发出的请求
+		echo, ok := parseEchoReply(reply[:n], p.config.IPVersion)
+		if !ok || echo.ID != (os.Getpid()&0xffff) {
+			continue
+		}
 
-	// 解析ICMP回复
-	replyMsg, err := icmp.ParseMessage(int(ipv4.ICMPTypeEchoReply), reply[:n])
-	if err != nil {
-		p.sendPingResult(target, math.NaN())
-		return
+		if p.handleEchoReply(target, seq, echo.Seq, receiveTime, tracker) {
+			return
+		}
 	}
+}
 
-	// 验证这是我们的回复
-	if echo, ok := replyMsg.Body.(*icmp.Echo); ok {
-		if echo.ID == (os.Getpid()&0xffff) && echo.Seq == seq {
-			// 成功收到回复，发送延迟结果（转换为毫秒）
-			latencyMs := float64(rtt.Nanoseconds()) / 1e6
-			p.sendPingResult(target, latencyMs)
-			return
+// parseEchoReply 解析ICMP回显应答报文
+// 非回显应答或解析失败时ok为false
+func parseEchoReply(data []byte, ipVersion int) (echo *icmp.Echo, ok bool) {
+	proto, replyType := 1, icmp.Type(ipv4.ICMPTypeEchoReply) // ICMPv4协议号
+	if ipVersion == 6 {
+		proto, replyType = 58, icmp.Type(ipv6.ICMPTypeEchoReply) // ICMPv6协议号
+	} else if len(data) >= ipv4.HeaderLen && data[0]>>4 == 4 {
+		// IPv4原始套接字读取到的数据包含IP头部，需要先跳过
+		headerLen := int(data[0]&0x0f) * 4
+		if headerLen > len(data) {
+			return nil, false
 		}
+		data = data[headerLen:]
+	}
+
+	msg, err := icmp.ParseMessage(proto, data)
+	if err != nil || msg.Type != replyType {
+		return nil, false
 	}
 
-	// 如果验证失败，记录为超时
-	p.sendPingResult(target, math.NaN())
+	echo, ok = msg.Body.(*icmp.Echo)
+	return echo, ok
 }
 
 // Stop 停止特权模式的pinger
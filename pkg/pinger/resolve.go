@@ -0,0 +1,89 @@
+// Package pinger 目标地址解析
+// 启动时解析目标地址，失败时退避重试；之后按配置的间隔重新解析，域名指向的地址变化时切换到新地址
+package pinger
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/Kevin-Rudy/goping/pkg/core"
+)
+
+// resolveRetryMax 地址解析失败后重试的最长间隔
+const resolveRetryMax = 30 * time.Second
+
+// resolveTarget 解析目标地址并上报解析结果
+func (bp *basePinger) resolveTarget(target string) (*net.IPAddr, error) {
+	dst, err := bp.lookup(bp.config.GetIPProtocol(), target)
+	if err != nil {
+		return nil, err
+	}
+	bp.reportAddress(target, dst.String())
+	return dst, nil
+}
+
+// waitResolved 解析目标地址，失败时按指数退避重试，直到解析成功或目标停止
+// 首次重试的等待时间为目标的探测间隔，之后逐次加倍，最长为resolveRetryMax；
+// 目标停止时返回false
+func (bp *basePinger) waitResolved(target string, stop <-chan struct{}) (*net.IPAddr, bool) {
+	delay, _ := bp.targetSettings(target)
+	for {
+		dst, err := bp.resolveTarget(target)
+		if err == nil {
+			return dst, true
+		}
+		bp.reportResolveFailure(target, fmt.Errorf("解析目标地址失败，%v后重试: %w", delay, err))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-bp.stopChan:
+			timer.Stop()
+			return nil, false
+		case <-stop:
+			timer.Stop()
+			return nil, false
+		case <-timer.C:
+		}
+		delay = min(delay*2, resolveRetryMax)
+	}
+}
+
+// lookupChanged 重新解析目标地址，地址与current不同时返回新地址和true
+// 重新解析失败时继续使用原地址，不视为探测错误
+func (bp *basePinger) lookupChanged(target string, current *net.IPAddr) (*net.IPAddr, bool) {
+	dst, err := bp.lookup(bp.config.GetIPProtocol(), target)
+	if err != nil || dst.String() == current.String() {
+		return current, false
+	}
+	return dst, true
+}
+
+// newResolveTicker 创建重新解析的定时器，未启用时返回nil通道
+func (bp *basePinger) newResolveTicker() (<-chan time.Time, func()) {
+	if bp.config.ResolveInterval <= 0 {
+		return nil, func() {}
+	}
+	ticker := time.NewTicker(bp.config.ResolveInterval)
+	return ticker.C, ticker.Stop
+}
+
+// reportAddress 记录目标当前使用的地址，地址变化（包括首次解析）时产生事件
+func (bp *basePinger) reportAddress(target, address string) {
+	bp.healthMu.Lock()
+	state := bp.getHealthState(target)
+	if state.address == address {
+		bp.healthMu.Unlock()
+		return
+	}
+	state.address = address
+	health := state.health
+	bp.healthMu.Unlock()
+
+	bp.sendEvent(core.SourceEvent{
+		Time:       time.Now(),
+		Identifier: target,
+		Health:     health,
+		Address:    address,
+	})
+}
@@ -0,0 +1,110 @@
+// Package pinger 序列号跟踪
+// 记录已发送探测包的序列号，用于识别重复、乱序和迟到的回复
+package pinger
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Kevin-Rudy/goping/pkg/core"
+)
+
+// defaultSeqWindow 默认保留的探测记录数量
+// ICMP序列号只有16位，窗口必须远小于65536以避免回绕后的误判
+const defaultSeqWindow = 1024
+
+// probeRecord 单个探测包的状态
+type probeRecord struct {
+	seq      int       // 完整（未回绕）的序列号
+	sendTime time.Time // 发送时间
+	replied  bool      // 是否已收到回复
+	expired  bool      // 是否已判定为超时
+}
+
+// seqTracker 跟踪单个目标的探测包序列号
+type seqTracker struct {
+	mu       sync.Mutex
+	probes   map[int]*probeRecord // 以报文中的16位序列号为键
+	highest  int                  // 已收到回复的最大完整序列号
+	window   int                  // 最多保留的探测记录数
+	lateLife time.Duration        // 超时的探测包从发送起仍接受迟到回复的时长
+}
+
+// newSeqTracker 创建序列号跟踪器
+// lateLife为超时时间与迟到宽限时间之和，超过该时长的超时探测包不再接受回复
+func newSeqTracker(window int, lateLife time.Duration) *seqTracker {
+	if window <= 0 {
+		window = defaultSeqWindow
+	}
+	return &seqTracker{
+		probes:   make(map[int]*probeRecord),
+		window:   window,
+		lateLife: lateLife,
+	}
+}
+
+// wireSeq 将完整序列号转换为报文中的16位序列号
+func wireSeq(seq int) int {
+	return seq & 0xffff
+}
+
+// sent 记录一次探测包的发送
+func (st *seqTracker) sent(seq int, sendTime time.Time) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.probes[wireSeq(seq)] = &probeRecord{seq: seq, sendTime: sendTime}
+
+	// 清理窗口外的旧记录
+	oldest := seq - st.window
+	for key, probe := range st.probes {
+		if probe.seq <= oldest {
+			delete(st.probes, key)
+		}
+	}
+}
+
+// expire 将探测包标记为超时，之后到达的回复将被视为迟到回复
+func (st *seqTracker) expire(seq int) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if probe, exists := st.probes[wireSeq(seq)]; exists && probe.seq == seq && !probe.replied {
+		probe.expired = true
+	}
+}
+
+// classify 对收到的回复进行分类
+// 返回回复分类、是否乱序、对应探测包的完整序列号和发送时间；
+// 序列号不在跟踪窗口内，或超时探测包已过宽限期时ok为false，调用方应忽略该回复
+func (st *seqTracker) classify(seqOnWire int, receiveTime time.Time) (kind core.ReplyKind, outOfOrder bool, seq int, sendTime time.Time, ok bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	probe, exists := st.probes[wireSeq(seqOnWire)]
+	if !exists {
+		return core.ReplyNormal, false, 0, time.Time{}, false
+	}
+
+	// 超时且已过宽限期的探测包按丢失处理，不再接受回复
+	if probe.expired && !probe.replied && receiveTime.Sub(probe.sendTime) > st.lateLife {
+		return core.ReplyNormal, false, 0, time.Time{}, false
+	}
+
+	if probe.replied {
+		return core.ReplyDuplicate, false, probe.seq, probe.sendTime, true
+	}
+	probe.replied = true
+
+	// 在更大序列号的回复之后才到达，视为乱序
+	outOfOrder = probe.seq < st.highest
+	if probe.seq > st.highest {
+		st.highest = probe.seq
+	}
+
+	kind = core.ReplyNormal
+	if probe.expired {
+		kind = core.ReplyLate
+	}
+	return kind, outOfOrder, probe.seq, probe.sendTime, true
+}
@@ -56,6 +56,7 @@ func newWindowsPinger(targets []string, config *Config) (core.DataSource, error)
 	p := &windowsPinger{
 		basePinger: newBasePinger(targets, config),
 	}
+	p.runTarget = p.pingTarget
 
 	// 创建ICMP句柄
 	ret, _, err := icmpCreateFile.Call()
@@ -67,46 +68,59 @@ func newWindowsPinger(targets []string, config *Config) (core.DataSource, error)
 	return p, nil
 }
 
-// Start 实现core.DataSource接口，启动ping操作
-func (p *windowsPinger) Start() {
-	p.setRunning(true)
-
-	// 为每个目标启动一个goroutine
-	for _, target := range p.targets {
-		p.wg.Add(1)
-		go p.pingTarget(target)
-	}
+// EngineInfo 实现core.EngineReporter接口
+func (p *windowsPinger) EngineInfo() (implementation, privilege string) {
+	return "Windows ICMP API", "普通用户模式"
 }
 
 // pingTarget 对单个目标进行ping操作
-func (p *windowsPinger) pingTarget(target string) {
-	defer p.wg.Done()
-
-	// 解析目标地址
-	dst, err := net.ResolveIPAddr(p.config.GetIPProtocol(), target)
-	if err != nil {
+func (p *windowsPinger) pingTarget(target string, stop <-chan struct{}) {
+	// 解析目标地址（地址已在NewPinger中预验证，此处失败属于临时网络问题，退避重试直到成功）
+	dst, ok := p.waitResolved(target, stop)
+	if !ok {
 		return
 	}
+	destAddr := ipv4ToUint32(dst.IP)
 
-	// 将IP地址转换为32位整数（网络字节序）
-	ip := dst.IP.To4()
-	destAddr := uint32(ip[0]) | (uint32(ip[1]) << 8) | (uint32(ip[2]) << 16) | (uint32(ip[3]) << 24)
+	// 获取该目标生效的间隔和超时（支持按目标覆盖）
+	interval, timeout := p.targetSettings(target)
 
-	ticker := time.NewTicker(p.config.Interval)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
+	resolveC, stopResolve := p.newResolveTicker()
+	defer stopResolve()
+
+	seq := 0
 
 	for {
 		select {
 		case <-p.stopChan:
 			return
+		case <-stop:
+			return
+		case <-resolveC:
+			if next, changed := p.lookupChanged(target, dst); changed {
+				dst = next
+				destAddr = ipv4ToUint32(dst.IP)
+				p.reportAddress(target, dst.String())
+			}
 		case <-ticker.C:
-			p.sendPing(destAddr, target)
+			seq++
+			interval, timeout = p.refreshSettings(target, ticker, interval)
+			p.sendPing(destAddr, target, seq, timeout)
 		}
 	}
 }
 
+// ipv4ToUint32 将IP地址转换为32位整数（网络字节序）
+func ipv4ToUint32(addr net.IP) uint32 {
+	ip := addr.To4()
+	return uint32(ip[0]) | (uint32(ip[1]) << 8) | (uint32(ip[2]) << 16) | (uint32(ip[3]) << 24)
+}
+
 // sendPing 发送单个ping包
-func (p *windowsPinger) sendPing(destAddr uint32, target string) {
+// IcmpSendEcho在系统内部完成请求与回复的匹配，重复和迟到的回复不会返回给调用方
+func (p *windowsPinger) sendPing(destAddr uint32, target string, seq int, timeout time.Duration) {
 	// 准备发送数据
 	sendData := []byte("goping")
 
@@ -116,7 +130,7 @@ func (p *windowsPinger) sendPing(destAddr uint32, target string) {
 	replyBuffer := make([]byte, replySize)
 
 	// 设置超时（毫秒）
-	timeoutMs := uint32(p.config.Timeout.Milliseconds())
+	timeoutMs := uint32(timeout.Milliseconds())
 
 	// 记录发送时间
 	sendTime := time.Now()
@@ -137,7 +151,7 @@ func (p *windowsPinger) sendPing(destAddr uint32, target string) {
 
 	if ret == 0 {
 		// 请求失败或超时 - 发送NaN作为延迟
-		p.sendPingResultWithTime(target, math.NaN(), sendTime, receiveTime)
+		p.sendWindowsResult(target, math.NaN(), sendTime, receiveTime, seq)
 		return
 	}
 
@@ -156,13 +170,24 @@ func (p *windowsPinger) sendPing(destAddr uint32, target string) {
 
 		// 发送延迟结果（转换为毫秒）
 		latencyMs := float64(rtt.Nanoseconds()) / 1e6
-		p.sendPingResultWithTime(target, latencyMs, sendTime, receiveTime)
+		p.sendWindowsResult(target, latencyMs, sendTime, receiveTime, seq)
 	} else {
 		// 回复有错误状态 - 发送NaN作为延迟
-		p.sendPingResultWithTime(target, math.NaN(), sendTime, receiveTime)
+		p.sendWindowsResult(target, math.NaN(), sendTime, receiveTime, seq)
 	}
 }
 
+// sendWindowsResult 发送带序列号的ping结果
+func (p *windowsPinger) sendWindowsResult(target string, latency float64, sendTime, receiveTime time.Time, seq int) {
+	p.sendResult(core.PingResult{
+		Identifier:  target,
+		Latency:     latency,
+		SendTime:    sendTime,
+		ReceiveTime: receiveTime,
+		Seq:         seq,
+	})
+}
+
 // Stop 停止Windows模式的pinger
 func (p *windowsPinger) Stop() {
 	// 调用基础的停止方法
@@ -0,0 +1,37 @@
+// Package tui 图表视图模式
+package tui
+
+// chartMode 图表的绘制模式
+type chartMode int
+
+const (
+	chartModeLine    chartMode = iota // 折线图
+	chartModeHeatmap                  // 延迟分布热力图
+	chartModeBand                     // smokeping风格的最小/中位/最大带状图
+	chartModeCount                    // 视图模式数量，用于循环切换
+)
+
+// String 返回视图模式的显示名称
+func (m chartMode) String() string {
+	switch m {
+	case chartModeHeatmap:
+		return "热力图"
+	case chartModeBand:
+		return "带状图"
+	default:
+		return "折线图"
+	}
+}
+
+// cycleChartMode 切换到下一个图表视图模式
+func (t *TUI) cycleChartMode() {
+	t.chartMode = (t.chartMode + 1) % chartModeCount
+}
+
+// chartTargets 返回当前图表需要绘制的目标：全选时为所有未隐藏的目标，否则为选中的目标
+func (t *TUI) chartTargets() []string {
+	if t.selectedRow >= 0 && t.selectedRow < len(t.identifiers) {
+		return []string{t.identifiers[t.selectedRow]}
+	}
+	return t.visibleTargets()
+}
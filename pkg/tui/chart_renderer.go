@@ -4,16 +4,17 @@ package tui
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/Kevin-Rudy/goping/pkg/core"
 )
 
-// brailleCell 定义盲文字符的cell结构
-type brailleCell struct {
-	char  int
-	color string
+// canvasCell 子像素画布中的一个字符格
+type canvasCell struct {
+	mask  int    // 已绘制的子像素掩码，由字符集合成为字符
+	color string // 最后绘制到该格的目标颜色
 }
 
 // validateChartSize 验证图表尺寸是否合理
@@ -55,6 +56,19 @@ func (t *TUI) calculateValueRange(targetDataPoints map[string][]core.DataPoint,
 		}
 	}
 
+	if t.logScale {
+		// 对数刻度：按比例留出缓冲，下界必须为正数
+		minVal = math.Max(minVal, logScaleFloor)
+		maxVal = math.Max(maxVal, minVal)
+		if maxVal == minVal {
+			maxVal *= 2
+			minVal /= 2
+		}
+		maxVal *= 1 + t.tuiConfig.ValueBufferRatio
+		minVal /= 1 + t.tuiConfig.ValueBufferRatio
+		return minVal, maxVal, maxVal - minVal, ""
+	}
+
 	// 如果所有值都一样，特殊处理
 	if maxVal == minVal {
 		maxVal++
@@ -77,14 +91,15 @@ func (t *TUI) calculateValueRange(targetDataPoints map[string][]core.DataPoint,
 }
 
 // drawSingleTargetChart 绘制单目标图表，基于时间戳
+// 调用者需持有statsMu
 func (t *TUI) drawSingleTargetChart(identifier string, width, height int) string {
 	targetDataPoints := make(map[string][]core.DataPoint)
 
-	t.statsMu.RLock()
-	if stats, exists := t.statsData[identifier]; exists && len(stats.History) > 0 {
-		targetDataPoints[identifier] = stats.History
+	windowStart, windowEnd := t.getTimeWindow()
+
+	if stats, exists := t.statsData[identifier]; exists && (len(stats.History) > 0 || len(stats.rollups) > 0) {
+		targetDataPoints[identifier] = t.chartPoints(stats, windowStart, windowEnd, width*t.glyphs.cellWidth)
 	}
-	t.statsMu.RUnlock()
 
 	if len(targetDataPoints) == 0 {
 		return "没有数据"
@@ -98,19 +113,20 @@ func (t *TUI) drawSingleTargetChart(identifier string, width, height int) string
 }
 
 // drawMultiTargetChart 绘制多目标对比图表，基于时间戳
+// 调用者需持有statsMu
 func (t *TUI) drawMultiTargetChart(width, height int) string {
 	allTargetDataPoints := make(map[string][]core.DataPoint)
 	colors := make(map[string]string)
 
-	t.statsMu.RLock()
-	// 使用排序后的标识符列表，确保颜色分配稳定
-	for _, identifier := range t.identifiers {
-		if stats, exists := t.statsData[identifier]; exists && len(stats.History) > 0 {
-			allTargetDataPoints[identifier] = stats.History
+	windowStart, windowEnd := t.getTimeWindow()
+
+	// 使用排序后的标识符列表，确保颜色分配稳定；隐藏的目标不参与绘制和Y轴自动缩放
+	for _, identifier := range t.visibleTargets() {
+		if stats, exists := t.statsData[identifier]; exists && (len(stats.History) > 0 || len(stats.rollups) > 0) {
+			allTargetDataPoints[identifier] = t.chartPoints(stats, windowStart, windowEnd, width*t.glyphs.cellWidth)
 			colors[identifier] = t.getTargetColor(identifier)
 		}
 	}
-	t.statsMu.RUnlock()
 
 	if len(allTargetDataPoints) == 0 {
 		return "没有数据"
@@ -130,19 +146,39 @@ func (t *TUI) drawChartWithTimestamps(targetDataPoints map[string][]core.DataPoi
 	windowStart, windowEnd := t.getTimeWindow()
 
 	// 计算值范围
-	minVal, maxVal, valueRange, err := t.calculateValueRange(targetDataPoints, windowStart, windowEnd)
+	minVal, maxVal, _, err := t.calculateValueRange(targetDataPoints, windowStart, windowEnd)
 	if err != "" {
 		return err
 	}
 
-	// 2. 动态计算Y轴标签宽度
-	topLabel := formatLatency(maxVal)
-	bottomLabel := formatLatency(minVal)
-	maxLabelLen := len(topLabel)
-	if len(bottomLabel) > maxLabelLen {
-		maxLabelLen = len(bottomLabel)
+	return t.drawChartWithCursor(targetDataPoints, colors, width, height, windowStart, windowEnd, minVal, maxVal)
+}
+
+// drawChartWithCursor 使用给定的时间窗口和值范围绘制折线图，并记录绘图区位置供鼠标坐标换算
+// 光标在窗口内时在图表上方显示读数行；绘图区的偏移相对本图左上角，由调用者换算到图表视图中的位置
+func (t *TUI) drawChartWithCursor(targetDataPoints map[string][]core.DataPoint, colors map[string]string, width, height int,
+	windowStart, windowEnd time.Time, minVal, maxVal float64) string {
+	labelWidth := t.chartLabelWidth(minVal, maxVal)
+	area := plotArea{left: labelWidth, width: width - labelWidth, height: height - 2, start: windowStart, end: windowEnd}
+	readout := t.cursorReadout(targetDataPoints, colors, windowStart, windowEnd, width-labelWidth, width)
+	if readout == "" {
+		t.plotAreas = append(t.plotAreas, area)
+		return t.drawChartWithRange(targetDataPoints, colors, width, height, windowStart, windowEnd, minVal, maxVal)
 	}
-	yAxisLabelWidth := maxLabelLen + 2 // +2 为│分隔符和右侧空格留出缓冲
+	area.top, area.height = 1, height-3
+	t.plotAreas = append(t.plotAreas, area)
+	return readout + "\n" + t.drawChartWithRange(targetDataPoints, colors, width, height-1, windowStart, windowEnd, minVal, maxVal)
+}
+
+// rangePattern 降采样时每列延迟范围的像素模式，用点线与平均值折线区分
+const rangePattern = "10"
+
+// drawChartWithRange 使用给定的时间窗口和值范围绘制折线图
+// 网格视图中的多个小图可以借此共享同一Y轴刻度
+func (t *TUI) drawChartWithRange(targetDataPoints map[string][]core.DataPoint, colors map[string]string, width, height int,
+	windowStart, windowEnd time.Time, minVal, maxVal float64) string {
+	// 2. 动态计算Y轴标签宽度
+	yAxisLabelWidth := t.chartLabelWidth(minVal, maxVal)
 
 	// 3. 准备画布尺寸
 	chartBodyHeight := height - 2 // 为X轴和时间戳留出2行空间
@@ -153,27 +189,23 @@ func (t *TUI) drawChartWithTimestamps(targetDataPoints map[string][]core.DataPoi
 		return "可绘制区域过小"
 	}
 
-	// 4. 创建盲文画布
-	canvas := make([][]brailleCell, chartWidth)
+	// 4. 创建子像素画布，每个字符格的子像素划分由字符集决定
+	glyphs := t.glyphs
+	pixelWidth := chartWidth * glyphs.cellWidth
+	pixelHeight := chartBodyHeight * glyphs.cellHeight
+	canvas := make([][]canvasCell, chartWidth)
 	for i := range canvas {
-		canvas[i] = make([]brailleCell, chartBodyHeight)
+		canvas[i] = make([]canvasCell, chartBodyHeight)
 	}
 
-	// 定义盲文点阵的映射关系 (2x4 grid)
-	brailleDotMap := [4][2]int{
-		{0b00000001, 0b00001000}, // (y:0, x:0), (y:0, x:1)
-		{0b00000010, 0b00010000}, // (y:1, x:0), (y:1, x:1)
-		{0b00000100, 0b00100000}, // (y:2, x:0), (y:2, x:1)
-		{0b01000000, 0b10000000}, // (y:3, x:0), (y:3, x:1)
-	}
-
-	// 5. 绘制所有目标，使用稳定的遍历顺序
-	for _, targetName := range t.identifiers {
+	// 5. 绘制所有目标，使用与表格排序无关的稳定顺序
+	for _, targetName := range t.drawOrder(targetDataPoints) {
 		dataPoints := targetDataPoints[targetName]
 		color := colors[targetName]
 		if color == "" {
 			color = "[white]"
 		}
+		pattern := t.linePattern(targetName)
 
 		if len(dataPoints) == 0 {
 			continue
@@ -183,6 +215,7 @@ func (t *TUI) drawChartWithTimestamps(targetDataPoints map[string][]core.DataPoi
 		// （注意：数据点应该已经是按时间排序的，但为了安全起见）
 
 		var lastValidX, lastValidY int = -1, -1
+		var rangeX, rangeY int = -1, -1 // 当前列中上一个范围点的坐标
 
 		for _, point := range dataPoints {
 			// 只处理在当前时间窗口内的数据点
@@ -191,8 +224,8 @@ func (t *TUI) drawChartWithTimestamps(targetDataPoints map[string][]core.DataPoi
 			}
 
 			// 计算X坐标（基于时间戳）
-			currX := t.timestampToX(point.Timestamp, windowStart, windowEnd, chartWidth*2) // 使用高分辨率
-			if currX < 0 || currX >= chartWidth*2 {
+			currX := t.timestampToX(point.Timestamp, windowStart, windowEnd, pixelWidth) // 使用子像素分辨率
+			if currX < 0 || currX >= pixelWidth {
 				continue // 跳过窗口外的点
 			}
 
@@ -203,35 +236,38 @@ func (t *TUI) drawChartWithTimestamps(targetDataPoints map[string][]core.DataPoi
 				currY = 0
 			} else {
 				// 正常值：按延迟值计算Y坐标
-				normalized := (point.Value - minVal) / valueRange
+				normalized := t.normalizeValue(point.Value, minVal, maxVal)
 				if math.IsNaN(normalized) || math.IsInf(normalized, 0) {
 					currY = 0 // 异常情况也画到顶部
 				} else {
-					currY = int((1.0 - normalized) * float64(chartBodyHeight*4-1))
+					currY = int((1.0 - normalized) * float64(pixelHeight-1))
 				}
 			}
 
 			// 边界检查（高分辨率坐标）
 			if currY < 0 {
 				currY = 0
-			} else if currY >= chartBodyHeight*4 {
-				currY = chartBodyHeight*4 - 1
+			} else if currY >= pixelHeight {
+				currY = pixelHeight - 1
+			}
+
+			// 降采样的范围点成对出现，在该列画出最小值到最大值的点线，不参与折线连接
+			if point.Status == core.PointRange {
+				if rangeX == currX {
+					t.drawCanvasLine(canvas, rangeX, rangeY, currX, currY, pixelHeight, pixelWidth, color, rangePattern)
+					rangeX, rangeY = -1, -1
+				} else {
+					rangeX, rangeY = currX, currY
+				}
+				continue
 			}
 
 			// 如果上一个有效点存在，绘制连接线
 			if lastValidX != -1 && lastValidY != -1 {
-				t.drawBrailleLineNew(canvas, lastValidX, lastValidY, currX, currY, chartBodyHeight*4, chartWidth*2, color)
+				t.drawCanvasLine(canvas, lastValidX, lastValidY, currX, currY, pixelHeight, pixelWidth, color, pattern)
 			} else {
 				// 如果这是线条的第一个点，直接在画布上标记
-				canvasX := currX / 2
-				canvasY := currY / 4
-				subY := currY % 4
-				subX := currX % 2
-
-				if canvasX >= 0 && canvasX < chartWidth && canvasY >= 0 && canvasY < chartBodyHeight {
-					canvas[canvasX][canvasY].char |= brailleDotMap[subY][subX]
-					canvas[canvasX][canvasY].color = color
-				}
+				t.plotPixel(canvas, currX, currY, color)
 			}
 
 			// 更新上一个有效点的坐标
@@ -242,24 +278,14 @@ func (t *TUI) drawChartWithTimestamps(targetDataPoints map[string][]core.DataPoi
 	// 6. 构建输出字符串
 	var lines []string
 
-	// 预先计算Y轴标签位置
-	yAxisLabelCount := 5
-	if chartBodyHeight < yAxisLabelCount {
-		yAxisLabelCount = chartBodyHeight
-	}
-
-	// 预先计算所有Y轴标签及其对应的像素行号
-	yAxisLabels := make(map[int]string)
-	if yAxisLabelCount > 1 {
-		for i := 0; i < yAxisLabelCount; i++ {
-			// 在数值上均匀分布
-			normalized := float64(i) / float64(yAxisLabelCount-1) // 0.0 到 1.0
-			value := maxVal - normalized*valueRange               // 从最大值到最小值
-			// 计算对应的像素行号
-			pixelRow := int(normalized * float64(chartBodyHeight-1))
-			yAxisLabels[pixelRow] = formatLatency(value)
-		}
-	}
+	// 预先计算所有Y轴标签及其对应的行号
+	yAxisLabels := t.yAxisLabels(minVal, maxVal, chartBodyHeight)
+
+	// 告警阈值参考线所在的行、光标所在的列和标记所在的列
+	thresholdRows := t.thresholdRows(minVal, maxVal, chartBodyHeight)
+	cursorCol := t.cursorColumn(windowStart, windowEnd, chartWidth)
+	markerCols := t.markerColumns(windowStart, windowEnd, chartWidth)
+	markerLabels := t.markerLabels(canvas, markerCols, chartWidth)
 
 	// 绘制Y轴和图表主体
 	for i := 0; i < chartBodyHeight; i++ {
@@ -270,30 +296,29 @@ func (t *TUI) drawChartWithTimestamps(targetDataPoints map[string][]core.DataPoi
 
 		for j := 0; j < chartWidth; j++ {
 			cell := canvas[j][i]
-			if cell.char == 0 {
-				line += " "
+			if cell.mask == 0 {
+				_, isMarker := markerCols[j]
+				if label, exists := markerLabels[j]; exists && i == 0 {
+					line += markerColor + label + "[white]" // 双宽字符占用的第二个单元格为空字符串
+				} else if j == cursorCol {
+					line += cursorColor + cursorLineChar + "[white]"
+				} else if isMarker {
+					line += markerColor + markerLineChar + "[white]"
+				} else if thresholdColor, exists := thresholdRows[i]; exists {
+					line += thresholdColor + thresholdLineChar + "[white]"
+				} else {
+					line += " "
+				}
 			} else {
 				// 确保颜色已经是tview格式，不需要再次添加方括号
-				line += cell.color + string(rune(0x2800+cell.char)) + "[white]"
+				line += cell.color + string(glyphs.glyph(cell.mask)) + "[white]"
 			}
 		}
 		lines = append(lines, line)
 	}
 
-	// 7. 绘制X轴
-	xAxisLine := fmt.Sprintf("%-*s└%s", yAxisLabelWidth-1, "", strings.Repeat("─", chartWidth))
-	lines = append(lines, "[gray]"+xAxisLine+"[white]")
-
-	// X轴时间刻度 - 显示实际的时间窗口
-	startTimeStr := windowStart.Format("15:04:05")
-	endTimeStr := windowEnd.Format("15:04:05")
-
-	spaceCount := chartWidth - len(startTimeStr) - len(endTimeStr)
-	if spaceCount < 1 {
-		spaceCount = 1
-	}
-	timeLine := fmt.Sprintf("%-*s%s%*s%s", yAxisLabelWidth, "", startTimeStr, spaceCount, "", endTimeStr)
-	lines = append(lines, "[gray]"+timeLine+"[white]")
+	// 7. 绘制X轴和时间刻度
+	lines = append(lines, t.drawTimeAxis(yAxisLabelWidth, chartWidth, windowStart, windowEnd)...)
 
 	// 保护性检查：确保输出不会超过可用高度，保证X轴总是可见
 	if len(lines) > height {
@@ -303,16 +328,21 @@ func (t *TUI) drawChartWithTimestamps(targetDataPoints map[string][]core.DataPoi
 	return strings.Join(lines, "\n")
 }
 
-// drawBrailleLineNew 使用布雷森汉姆算法在盲文画布上绘制线段
-func (t *TUI) drawBrailleLineNew(canvas [][]brailleCell, x1, y1, x2, y2, maxHeight, chartWidth int, color string) {
-	// 定义盲文点阵的映射关系 (2x4 grid)
-	brailleDotMap := [4][2]int{
-		{0b00000001, 0b00001000}, // (y:0, x:0), (y:0, x:1)
-		{0b00000010, 0b00010000}, // (y:1, x:0), (y:1, x:1)
-		{0b00000100, 0b00100000}, // (y:2, x:0), (y:2, x:1)
-		{0b01000000, 0b10000000}, // (y:3, x:0), (y:3, x:1)
+// plotPixel 在子像素画布上标记(x, y)处的子像素，超出画布的坐标忽略
+func (t *TUI) plotPixel(canvas [][]canvasCell, x, y int, color string) {
+	glyphs := t.glyphs
+	canvasX := x / glyphs.cellWidth
+	canvasY := y / glyphs.cellHeight
+	if x < 0 || y < 0 || canvasX >= len(canvas) || canvasY >= len(canvas[canvasX]) {
+		return
 	}
+	canvas[canvasX][canvasY].mask |= glyphs.dots[y%glyphs.cellHeight][x%glyphs.cellWidth]
+	canvas[canvasX][canvasY].color = color
+}
 
+// drawCanvasLine 使用布雷森汉姆算法在子像素画布上绘制线段
+// pattern为线型的像素模式，为空表示实线；模式按线段主方向上的绝对坐标取值，使相邻线段的虚线保持连续
+func (t *TUI) drawCanvasLine(canvas [][]canvasCell, x1, y1, x2, y2, maxHeight, maxWidth int, color, pattern string) {
 	dx := abs(x2 - x1)
 	dy := abs(y2 - y1)
 	sx := 1
@@ -327,21 +357,16 @@ func (t *TUI) drawBrailleLineNew(canvas [][]brailleCell, x1, y1, x2, y2, maxHeig
 
 	x, y := x1, y1
 	for {
-		// 在当前位置放置字符
-		if y >= 0 && y < maxHeight && x >= 0 && x < chartWidth {
-			// 计算子像素位置
-			subY := y % 4 // 盲文字符内的垂直位置 (0-3)
-			subX := x % 2 // 盲文字符内的水平位置 (0-1)
-
-			// 计算画布坐标（每个盲文字符覆盖2x4个子像素）
-			canvasX := x / 2
-			canvasY := y / 4
-
-			// 确保画布坐标在有效范围内
-			if canvasX >= 0 && canvasX < chartWidth && canvasY >= 0 && canvasY < len(canvas[0]) {
-				canvas[canvasX][canvasY].char |= brailleDotMap[subY][subX]
-				canvas[canvasX][canvasY].color = color
-			}
+		// 按线型跳过部分像素
+		position := x
+		if dy > dx {
+			position = y
+		}
+		visible := pattern == "" || pattern[position%len(pattern)] == '1'
+
+		// 在当前位置放置子像素
+		if visible && y >= 0 && y < maxHeight && x >= 0 && x < maxWidth {
+			t.plotPixel(canvas, x, y, color)
 		}
 
 		// 检查是否到达终点
@@ -361,4 +386,193 @@ func (t *TUI) drawBrailleLineNew(canvas [][]brailleCell, x1, y1, x2, y2, maxHeig
 		}
 	}
 }
- 
\ No newline at end of file
+ 
+// bandColumn 带状图中一列（一个时间桶）的统计
+type bandColumn struct {
+	min, max, median float64
+	count, lost      int
+}
+
+// bandLegend 带状图的丢包颜色图例
+const bandLegend = "[gray]丢包: [green]0 [yellow]<10% [orange]<50% [red]≥50%[white]"
+
+// bandColumns 按列统计目标的最小/中位/最大延迟和丢包数，调用方需持有statsMu
+func (t *TUI) bandColumns(stats *targetStats, windowStart, windowEnd time.Time, chartWidth int) []bandColumn {
+	columns := make([]bandColumn, chartWidth)
+
+	if t.useRollups(stats, windowStart) {
+		// 聚合历史按直方图保留了延迟分布，中位数取所在直方图桶的代表值，并限制在该列的实际范围内
+		for i, bucket := range downsampleRollups(stats.rollups, windowStart, windowEnd, chartWidth) {
+			median := math.NaN()
+			if bucket.count > 0 {
+				median = math.Min(math.Max(bucket.histogram().quantile(0.5), bucket.min), bucket.max)
+			}
+			columns[i] = bandColumn{min: bucket.min, max: bucket.max, median: median, count: bucket.count, lost: bucket.lost}
+		}
+		return columns
+	}
+
+	samples := make([][]float64, chartWidth)
+	for _, point := range stats.History {
+		if point.Status == core.PointInterpolated || point.Status == core.PointPending {
+			continue
+		}
+		col := displayBucketIndex(point.Timestamp, windowStart, windowEnd, chartWidth)
+		if col < 0 {
+			continue
+		}
+		if math.IsNaN(point.Value) || math.IsInf(point.Value, 0) {
+			columns[col].lost++
+			continue
+		}
+		samples[col] = append(samples[col], point.Value)
+	}
+
+	for col, values := range samples {
+		if len(values) == 0 {
+			continue
+		}
+		sort.Float64s(values)
+		median := values[len(values)/2]
+		if len(values)%2 == 0 {
+			median = (values[len(values)/2-1] + median) / 2
+		}
+		columns[col].min = values[0]
+		columns[col].max = values[len(values)-1]
+		columns[col].median = median
+		columns[col].count = len(values)
+	}
+	return columns
+}
+
+// lossColor 根据丢包比例返回中位线的颜色
+func lossColor(lost, total int) string {
+	if total == 0 || lost == 0 {
+		return "[green]"
+	}
+	ratio := float64(lost) / float64(total)
+	switch {
+	case ratio < 0.1:
+		return "[yellow]"
+	case ratio < 0.5:
+		return "[orange]"
+	default:
+		return "[red]"
+	}
+}
+
+// drawBandChart 绘制smokeping风格的带状图
+// 每个目标一个条带：每列的最小到最大延迟范围画成阴影带，中位数画成线，
+// 中位线的颜色表示该时间段的丢包比例，全部丢失的列在顶部标记
+// 调用者需持有statsMu
+func (t *TUI) drawBandChart(identifiers []string, width, height int) string {
+	if sizeErr := t.validateChartSize(width, height); sizeErr != "" {
+		return sizeErr
+	}
+
+	windowStart, windowEnd := t.getTimeWindow()
+
+	targetDataPoints := make(map[string][]core.DataPoint)
+	var drawn []string
+	for _, identifier := range identifiers {
+		if stats, exists := t.statsData[identifier]; exists && (len(stats.History) > 0 || len(stats.rollups) > 0) {
+			targetDataPoints[identifier] = t.distributionPoints(stats, windowStart, windowEnd, width)
+			drawn = append(drawn, identifier)
+		}
+	}
+	if len(drawn) == 0 {
+		return "没有数据"
+	}
+
+	minVal, maxVal, _, errMsg := t.calculateValueRange(targetDataPoints, windowStart, windowEnd)
+	if errMsg != "" {
+		return errMsg
+	}
+
+	topLabel := formatLatency(maxVal)
+	bottomLabel := formatLatency(minVal)
+	maxLabelLen := len(topLabel)
+	if len(bottomLabel) > maxLabelLen {
+		maxLabelLen = len(bottomLabel)
+	}
+	yAxisLabelWidth := maxLabelLen + 2
+
+	chartBodyHeight := height - 2 // 为X轴和时间戳留出2行空间
+	chartWidth := width - yAxisLabelWidth
+	stripHeight := chartBodyHeight / len(drawn)
+	if chartWidth <= 0 || stripHeight < 3 {
+		return "可绘制区域过小"
+	}
+
+	// 中位数以字符格中同一行的子像素组成的水平短线表示
+	glyphs := t.glyphs
+
+	var lines []string
+	for i, identifier := range drawn {
+		color := t.getTargetColor(identifier)
+		columns := t.bandColumns(t.statsData[identifier], windowStart, windowEnd, chartWidth)
+
+		title := fmt.Sprintf("[gray]%*s │[white]%s%s[white]", yAxisLabelWidth-2, "", color, identifier)
+		if i == 0 {
+			title += "  " + bandLegend
+		}
+		lines = append(lines, title)
+
+		rows := stripHeight - 1
+		subRows := rows * glyphs.cellHeight
+		toSubRow := func(value float64) int {
+			y := int((1.0 - t.normalizeValue(value, minVal, maxVal)) * float64(subRows-1))
+			if y < 0 {
+				return 0
+			}
+			if y >= subRows {
+				return subRows - 1
+			}
+			return y
+		}
+
+		thresholdRows := t.thresholdRows(minVal, maxVal, rows)
+		grid := make([][]string, rows)
+		for r := range grid {
+			grid[r] = make([]string, chartWidth)
+			for col := range grid[r] {
+				grid[r][col] = " "
+				if thresholdColor, exists := thresholdRows[r]; exists {
+					grid[r][col] = thresholdColor + thresholdLineChar
+				}
+			}
+		}
+		for col, column := range columns {
+			if column.count == 0 {
+				if column.lost > 0 {
+					grid[0][col] = "[red]" + glyphs.lossMark
+				}
+				continue
+			}
+			for r := toSubRow(column.max) / glyphs.cellHeight; r <= toSubRow(column.min)/glyphs.cellHeight; r++ {
+				grid[r][col] = color + glyphs.fill
+			}
+			median := toSubRow(column.median)
+			grid[median/glyphs.cellHeight][col] = lossColor(column.lost, column.lost+column.count) +
+				string(glyphs.glyph(glyphs.rowMask(median%glyphs.cellHeight)))
+		}
+
+		for r, cells := range grid {
+			label := ""
+			switch r {
+			case 0:
+				label = topLabel
+			case rows - 1:
+				label = bottomLabel
+			}
+			lines = append(lines, fmt.Sprintf("[gray]%*s │", yAxisLabelWidth-2, label)+strings.Join(cells, "")+"[white]")
+		}
+	}
+	for len(lines) < chartBodyHeight {
+		lines = append(lines, fmt.Sprintf("[gray]%*s │[white]", yAxisLabelWidth-2, ""))
+	}
+
+	lines = append(lines, t.drawTimeAxis(yAxisLabelWidth, chartWidth, windowStart, windowEnd)...)
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,231 @@
+// Package tui 表格列定义与排序模块
+// 表格显示哪些统计列由配置决定，行可以按任意列排序；排序只改变行的顺序，图表颜色仍按目标分配
+package tui
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/Kevin-Rudy/goping/pkg/core"
+)
+
+// column 表格中的一个统计列
+type column struct {
+	name   string                           // 配置和命令中使用的列名
+	header string                           // 表头，同时也是Summary中的键
+	value  func(stats *targetStats) float64 // 排序用的数值，NaN表示没有数据，始终排在最后
+}
+
+// columns 所有可选的统计列，顺序即帮助中的列出顺序
+var columns = []column{
+	{"status", "状态", healthRank},
+	{"timeouts", "t/o", func(s *targetStats) float64 { return float64(s.PacketsSent - s.PacketsRecv) }},
+	{"loss", "丢包率", lossRate},
+	{"late", "迟到", func(s *targetStats) float64 { return float64(s.LateReplies) }},
+	{"sent", "发送/接收", func(s *targetStats) float64 { return float64(s.PacketsSent) }},
+	{"last", "最近延迟", lastLatency},
+	{"avg", "平均延迟", func(s *targetStats) float64 { return latencyOrNaN(s, s.WelfordMean) }},
+	{"min", "最小延迟", func(s *targetStats) float64 { return latencyOrNaN(s, s.MinLatency) }},
+	{"max", "最大延迟", func(s *targetStats) float64 { return latencyOrNaN(s, s.MaxLatency) }},
+	{"stddev", "标准差", stdDev},
+	{"p50", "P50", quantileColumn(0.50)},
+	{"p90", "P90", quantileColumn(0.90)},
+	{"p95", "P95", quantileColumn(0.95)},
+	{"p99", "P99", quantileColumn(0.99)},
+	{"dup", "重复", func(s *targetStats) float64 { return float64(s.Duplicates) }},
+	{"reorder", "乱序", func(s *targetStats) float64 { return float64(s.Reordered) }},
+}
+
+// defaultColumns 默认显示的统计列
+var defaultColumns = []string{"status", "timeouts", "loss", "late", "sent", "avg", "min", "max", "dup", "reorder"}
+
+// ColumnNames 返回所有可选的列名
+func ColumnNames() []string {
+	names := make([]string, 0, len(columns))
+	for _, col := range columns {
+		names = append(names, col.name)
+	}
+	return names
+}
+
+// findColumn 按列名查找统计列
+func findColumn(name string) (column, bool) {
+	for _, col := range columns {
+		if col.name == name {
+			return col, true
+		}
+	}
+	return column{}, false
+}
+
+// validateColumns 检查列名是否都存在且没有重复
+func validateColumns(names []string) error {
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if _, ok := findColumn(name); !ok {
+			return fmt.Errorf("未知的表格列 '%s'", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("表格列 '%s' 重复", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// tableColumns 返回配置中要显示的统计列
+func (t *TUI) tableColumns() []column {
+	result := make([]column, 0, len(t.tuiConfig.Columns))
+	for _, name := range t.tuiConfig.Columns {
+		if col, ok := findColumn(name); ok {
+			result = append(result, col)
+		}
+	}
+	return result
+}
+
+// healthRank 健康状态的排序值，降序时异常的目标排在前面
+func healthRank(stats *targetStats) float64 {
+	switch stats.Health {
+	case core.HealthUp:
+		return 0
+	case core.HealthDown:
+		return 2
+	case core.HealthError:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// latencyOrNaN 没有成功样本时返回NaN，否则返回value
+func latencyOrNaN(stats *targetStats, value float64) float64 {
+	if stats.WelfordCount == 0 {
+		return math.NaN()
+	}
+	return value
+}
+
+// lastLatency 返回最近一次收到回复的延迟，没有时返回NaN
+func lastLatency(stats *targetStats) float64 {
+	for i := len(stats.History) - 1; i >= 0; i-- {
+		point := stats.History[i]
+		if (point.Status == core.PointSuccess || point.Status == core.PointLate) && !math.IsNaN(point.Value) {
+			return point.Value
+		}
+	}
+	return math.NaN()
+}
+
+// stdDev 返回延迟的样本标准差，样本少于2个时返回NaN
+func stdDev(stats *targetStats) float64 {
+	if stats.WelfordCount < 2 {
+		return math.NaN()
+	}
+	return math.Sqrt(stats.WelfordM2 / float64(stats.WelfordCount-1))
+}
+
+// quantileColumn 返回计算q分位延迟的列取值函数
+func quantileColumn(q float64) func(stats *targetStats) float64 {
+	return func(stats *targetStats) float64 {
+		if stats.distribution == nil {
+			return math.NaN()
+		}
+		return stats.distribution.quantile(q)
+	}
+}
+
+// cycleSortColumn 切换排序列：原始顺序 → 第一个显示的列 → ... → 最后一个显示的列 → 原始顺序
+// 切换到新的列时默认降序，便于把丢包率、延迟最高的目标排在前面
+func (t *TUI) cycleSortColumn() {
+	visible := t.tableColumns()
+	next := 0
+	for i, col := range visible {
+		if col.name == t.sortColumn {
+			next = i + 1
+			break
+		}
+	}
+	if next >= len(visible) {
+		t.sortColumn = ""
+		t.setMessage("[green]按原始顺序排列[white]")
+		return
+	}
+	t.sortColumn = visible[next].name
+	t.sortDescending = true
+	t.setMessage(fmt.Sprintf("[green]按%s%s排列[white]", visible[next].header, sortDirectionName(t.sortDescending)))
+}
+
+// reverseSort 反转排序方向
+func (t *TUI) reverseSort() {
+	col, ok := findColumn(t.sortColumn)
+	if !ok {
+		t.setMessage("[yellow]请先按s选择排序列[white]")
+		return
+	}
+	t.sortDescending = !t.sortDescending
+	t.setMessage(fmt.Sprintf("[green]按%s%s排列[white]", col.header, sortDirectionName(t.sortDescending)))
+}
+
+// setSort 设置排序列和方向，name为空表示恢复原始顺序
+func (t *TUI) setSort(name string, descending bool) error {
+	if name != "" {
+		if _, ok := findColumn(name); !ok {
+			return fmt.Errorf("未知的表格列 '%s'", name)
+		}
+	}
+	t.sortColumn = name
+	t.sortDescending = descending
+	return nil
+}
+
+// sortDirectionName 返回排序方向的显示名称
+func sortDirectionName(descending bool) string {
+	if descending {
+		return "降序"
+	}
+	return "升序"
+}
+
+// sortIdentifiers 按当前排序列对目标排序，值相同的目标保持原始顺序
+// 调用者需持有statsMu
+func (t *TUI) sortIdentifiers(identifiers []string) {
+	col, ok := findColumn(t.sortColumn)
+	if !ok {
+		return
+	}
+	values := make(map[string]float64, len(identifiers))
+	for _, identifier := range identifiers {
+		values[identifier] = col.value(t.statsData[identifier])
+	}
+	sort.SliceStable(identifiers, func(i, j int) bool {
+		a, b := values[identifiers[i]], values[identifiers[j]]
+		if math.IsNaN(a) || math.IsNaN(b) {
+			return !math.IsNaN(a) && math.IsNaN(b)
+		}
+		if t.sortDescending {
+			return a > b
+		}
+		return a < b
+	})
+}
+
+// setIdentifiers 更新表格中的目标顺序，选中的目标在排序后保持选中
+func (t *TUI) setIdentifiers(identifiers []string) {
+	selected := t.selectedIdentifier()
+	t.sortIdentifiers(identifiers)
+	t.identifiers = identifiers
+
+	if selected != "" {
+		for i, identifier := range identifiers {
+			if identifier == selected {
+				t.selectedRow = i
+				return
+			}
+		}
+	}
+	if t.selectedRow >= len(t.identifiers) {
+		t.selectedRow = len(t.identifiers) - 1
+	}
+}
@@ -3,19 +3,40 @@ package tui
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
 // Config TUI组件的配置结构
 type Config struct {
-	RefreshInterval    time.Duration // UI刷新间隔
-	TimeGridInterval   time.Duration // 时间网格维护间隔
-	TimeoutBufferRatio float64       // 超时缓冲比例，TUI超时 = Pinger超时 * 此比例
-	MinChartWidth      int           // 最小图表宽度
-	MinChartHeight     int           // 最小图表高度
-	MaxHistorySize     int           // 历史缓冲区大小
-	ValueBufferRatio   float64       // 值缓冲比例
-	MaxChartSize       int           // 最大图表尺寸（防止极端值）
+	RefreshInterval    time.Duration   // UI刷新间隔
+	TimeGridInterval   time.Duration   // 时间网格维护间隔
+	TimeoutBufferRatio float64         // 超时缓冲比例，TUI超时 = Pinger超时 * 此比例
+	MinChartWidth      int             // 最小图表宽度
+	MinChartHeight     int             // 最小图表高度
+	MaxHistorySize     int             // 历史缓冲区大小（图表时间窗口包含的网格数）
+	HistoryRetention   time.Duration   // 历史数据保留时长，用于暂停后回看，不足一个时间窗口时按一个窗口保留
+	RollupInterval     time.Duration   // 长期历史的聚合桶宽度
+	RollupRetention    time.Duration   // 长期聚合历史的保留时长
+	ZoomLevels         []time.Duration // 可切换的图表时间窗口（默认窗口之外的缩放级别）
+	ValueBufferRatio   float64         // 值缓冲比例
+	LogScale           bool            // Y轴是否默认使用对数刻度
+	RelativeTime       bool            // X轴是否默认以相对当前时刻的时间（如-30s）标注，否则使用时钟时间
+	LatencyWarning     float64         // 最近探测平均延迟的警告阈值(ms)，0表示不启用
+	LatencyCritical    float64         // 最近探测平均延迟的严重阈值(ms)，0表示不启用
+	LossWarning        float64         // 最近探测丢包率的警告阈值(%)，0表示不启用
+	LossCritical       float64         // 最近探测丢包率的严重阈值(%)，0表示不启用
+	MaxChartSize       int             // 最大图表尺寸（防止极端值）
+	EventLogSize       int             // 事件日志面板保留的事件条数
+	EventLogFile       string          // 事件日志文件路径，为空表示不写文件
+	Columns            []string        // 表格中显示的统计列及其顺序，可选列名见ColumnNames
+	SortColumn         string          // 初始排序列名，为空表示按目标的原始顺序
+	SortDescending     bool            // 初始排序是否降序
+	Theme              string          // 内置颜色主题名称，可选名称见ThemeNames
+	CustomTheme        *Theme          // 自定义颜色主题（如从主题文件加载），设置后覆盖Theme
+	Renderer           string          // 折线图字符集：auto、braille、block、ascii，auto表示根据终端环境自动选择
+	StatusBar          bool            // 是否在底部显示状态栏
 }
 
 // DefaultConfig 返回默认配置
@@ -27,8 +48,17 @@ func DefaultConfig() *Config {
 		MinChartWidth:      20,                     // 最小图表宽度
 		MinChartHeight:     5,                      // 最小图表高度
 		MaxHistorySize:     150,                    // 默认150个历史点
-		ValueBufferRatio:   0.1,                    // 10%缓冲
-		MaxChartSize:       1000,                   // 最大图表尺寸
+		HistoryRetention:   10 * time.Minute,       // 默认保留10分钟历史用于回看
+		RollupInterval:     10 * time.Second,       // 默认每10秒聚合一个桶
+		RollupRetention:    24 * time.Hour,         // 默认保留24小时聚合历史
+		ZoomLevels:         []time.Duration{time.Minute, 5 * time.Minute, time.Hour, 24 * time.Hour},
+		ValueBufferRatio:   0.1,  // 10%缓冲
+		MaxChartSize:       1000, // 最大图表尺寸
+		EventLogSize:       500,  // 默认保留500条事件
+		Columns:            append([]string(nil), defaultColumns...),
+		Theme:              "dark", // 默认深色主题
+		Renderer:           "auto", // 默认根据终端环境选择字符集
+		StatusBar:          true,
 	}
 }
 
@@ -38,6 +68,18 @@ func (c *Config) GetTimeoutThreshold(pingerTimeout time.Duration) time.Duration
 	return time.Duration(float64(pingerTimeout) * c.TimeoutBufferRatio)
 }
 
+// resolveTheme 返回配置使用的颜色主题，未知的主题名称使用深色主题
+func (c *Config) resolveTheme() *Theme {
+	if c.CustomTheme != nil {
+		return c.CustomTheme
+	}
+	if theme, ok := BuiltinTheme(c.Theme); ok {
+		return theme
+	}
+	theme, _ := BuiltinTheme("dark")
+	return theme
+}
+
 // Validate 验证配置的合理性
 func (c *Config) Validate() error {
 	if c.RefreshInterval <= 0 {
@@ -80,13 +122,73 @@ func (c *Config) Validate() error {
 		return errors.New("历史缓冲区大小不能超过1000")
 	}
 
+	if c.HistoryRetention < 0 {
+		return errors.New("历史保留时长不能为负数")
+	}
+
+	if c.RollupInterval <= 0 {
+		return errors.New("聚合桶宽度必须大于0")
+	}
+
+	if c.RollupRetention < 0 {
+		return errors.New("聚合历史保留时长不能为负数")
+	}
+
+	for _, level := range c.ZoomLevels {
+		if level <= 0 {
+			return errors.New("缩放级别必须大于0")
+		}
+	}
+
 	if c.ValueBufferRatio < 0 {
 		return errors.New("值缓冲比例不能为负数")
 	}
 
+	if c.LatencyWarning < 0 || c.LatencyCritical < 0 {
+		return errors.New("延迟阈值不能为负数")
+	}
+
+	if c.LatencyWarning > 0 && c.LatencyCritical > 0 && c.LatencyCritical < c.LatencyWarning {
+		return errors.New("延迟严重阈值不能小于警告阈值")
+	}
+
+	if c.LossWarning < 0 || c.LossCritical < 0 || c.LossWarning > 100 || c.LossCritical > 100 {
+		return errors.New("丢包率阈值必须在0到100之间")
+	}
+
+	if c.LossWarning > 0 && c.LossCritical > 0 && c.LossCritical < c.LossWarning {
+		return errors.New("丢包率严重阈值不能小于警告阈值")
+	}
+
 	if c.MaxChartSize <= 0 {
 		return errors.New("最大图表尺寸必须大于0")
 	}
 
+	if c.EventLogSize <= 0 {
+		return errors.New("事件日志条数必须大于0")
+	}
+
+	if err := validateColumns(c.Columns); err != nil {
+		return err
+	}
+
+	if c.CustomTheme != nil {
+		if err := c.CustomTheme.Validate(); err != nil {
+			return err
+		}
+	} else if _, ok := builtinThemes[c.Theme]; !ok {
+		return fmt.Errorf("未知的主题 '%s'，可选: %s", c.Theme, strings.Join(themeNames, ", "))
+	}
+
+	if !containsString(rendererNames, c.Renderer) {
+		return fmt.Errorf("未知的图表字符集 '%s'，可选: %s", c.Renderer, strings.Join(rendererNames, ", "))
+	}
+
+	if c.SortColumn != "" {
+		if _, ok := findColumn(c.SortColumn); !ok {
+			return fmt.Errorf("未知的排序列 '%s'", c.SortColumn)
+		}
+	}
+
 	return nil
 }
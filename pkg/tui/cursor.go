@@ -0,0 +1,189 @@
+// Package tui 图表十字光标模块
+// 光标以距窗口结束时间的偏移表示，实时视图滚动时光标停留在图表的同一位置
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Kevin-Rudy/goping/pkg/core"
+	"github.com/rivo/tview"
+)
+
+const (
+	cursorLineChar = "┊"      // 光标竖线字符
+	cursorColor    = "[aqua]" // 光标和读数的颜色
+)
+
+// plotArea 折线图的绘图区，用于将鼠标坐标换算为时间
+type plotArea struct {
+	left, width int       // 绘图区相对图表视图左边的偏移和宽度（字符列）
+	top, height int       // 绘图区相对图表视图上边的偏移和高度（字符行）
+	start, end  time.Time // 绘图区对应的时间窗口
+}
+
+// plotAreaAt 返回包含图表视图中(x, y)位置的绘图区
+func (t *TUI) plotAreaAt(x, y int) (plotArea, bool) {
+	for _, area := range t.plotAreas {
+		if x >= area.left && x < area.left+area.width && y >= area.top && y < area.top+area.height {
+			return area, true
+		}
+	}
+	return plotArea{}, false
+}
+
+// setCursorColumn 将光标放到绘图区的第column列（列中点），返回该列是否在绘图区内
+// 光标以时间表示，网格视图中所有小图的光标位于同一时刻
+func (t *TUI) setCursorColumn(area plotArea, column int) bool {
+	if area.width <= 0 || column < 0 || column >= area.width {
+		return false
+	}
+	window := area.end.Sub(area.start)
+	t.cursorOffset = time.Duration(float64(window) * (1 - (float64(column)+0.5)/float64(area.width)))
+	t.cursorShown = true
+	return true
+}
+
+// hideCursor 隐藏光标，光标模式下光标始终显示
+func (t *TUI) hideCursor() {
+	if !t.cursorMode {
+		t.cursorShown = false
+	}
+}
+
+// toggleCursorMode 进入或退出光标模式，进入时光标位于窗口中间
+func (t *TUI) toggleCursorMode() {
+	t.cursorMode = !t.cursorMode
+	t.cursorShown = t.cursorMode
+	if t.cursorMode {
+		t.cursorOffset = t.windowDuration() / 2
+	}
+}
+
+// exitCursorMode 退出光标模式并隐藏光标
+func (t *TUI) exitCursorMode() {
+	t.cursorMode = false
+	t.cursorShown = false
+}
+
+// moveCursor 将光标移动若干列，正数向右（较新的时间），光标不会移出时间窗口
+// 列宽取最近一次绘制的绘图区，没有折线图时按100列计算
+func (t *TUI) moveCursor(columns int) {
+	width := 100
+	if len(t.plotAreas) > 0 {
+		width = t.plotAreas[0].width
+	}
+	window := t.windowDuration()
+	step := window / time.Duration(width)
+
+	offset := t.cursorOffset - time.Duration(columns)*step
+	if offset < step/2 {
+		offset = step / 2
+	}
+	if offset > window-step/2 {
+		offset = window - step/2
+	}
+	t.cursorOffset = offset
+}
+
+// cursorTime 返回光标在给定窗口中对应的时间，光标未显示或不在窗口内时返回false
+func (t *TUI) cursorTime(windowStart, windowEnd time.Time) (time.Time, bool) {
+	if !t.cursorShown {
+		return time.Time{}, false
+	}
+	timestamp := windowEnd.Add(-t.cursorOffset)
+	if timestamp.Before(windowStart) || timestamp.After(windowEnd) {
+		return time.Time{}, false
+	}
+	return timestamp, true
+}
+
+// cursorColumn 返回光标在宽度为width的绘图区中的列，光标未显示时返回-1
+func (t *TUI) cursorColumn(windowStart, windowEnd time.Time, width int) int {
+	timestamp, ok := t.cursorTime(windowStart, windowEnd)
+	if !ok || width <= 0 {
+		return -1
+	}
+	column := int(float64(timestamp.Sub(windowStart)) / float64(windowEnd.Sub(windowStart)) * float64(width))
+	if column >= width {
+		column = width - 1
+	}
+	return column
+}
+
+// nearestPoint 返回时间戳最接近timestamp且相差不超过tolerance的数据点
+// 等待中的点还没有结果，不参与查找
+func nearestPoint(points []core.DataPoint, timestamp time.Time, tolerance time.Duration) (core.DataPoint, bool) {
+	var nearest core.DataPoint
+	best := time.Duration(-1)
+	for _, point := range points {
+		// 范围点与同一列的平均值点时间相同，读数显示平均值
+		if point.Status == core.PointPending || point.Status == core.PointRange {
+			continue
+		}
+		diff := point.Timestamp.Sub(timestamp)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff <= tolerance && (best < 0 || diff < best) {
+			nearest, best = point, diff
+		}
+	}
+	return nearest, best >= 0
+}
+
+// cursorReadout 生成光标处的读数行：光标时间，以及每个目标在该时刻最近的数据点的延迟、状态和时间
+// 读数行超过maxWidth时（如网格视图中的小图）省略时间和目标名称，只显示各目标的延迟和状态
+func (t *TUI) cursorReadout(targetDataPoints map[string][]core.DataPoint, colors map[string]string, windowStart, windowEnd time.Time, chartWidth, maxWidth int) string {
+	timestamp, ok := t.cursorTime(windowStart, windowEnd)
+	if !ok {
+		return ""
+	}
+
+	// 在光标所在列的时间范围内查找数据点，探测间隔大于列宽时放宽到半个探测间隔
+	columnTolerance := windowEnd.Sub(windowStart) / time.Duration(2*chartWidth)
+	parts := []string{fmt.Sprintf("%s%s %s[white]", cursorColor, cursorLineChar, timestamp.Format("15:04:05.000"))}
+	compact := []string{cursorColor + cursorLineChar + "[white]"}
+	for _, identifier := range t.identifiers {
+		points, exists := targetDataPoints[identifier]
+		if !exists {
+			continue
+		}
+		tolerance := columnTolerance
+		if half := t.targetInterval(identifier) / 2; half > tolerance {
+			tolerance = half
+		}
+		value, pointTime := "[gray]-[white]", ""
+		if point, found := nearestPoint(points, timestamp, tolerance); found {
+			value = formatCursorPoint(point)
+			pointTime = fmt.Sprintf(" [gray]@%s[white]", point.Timestamp.Format("15:04:05.000"))
+		}
+		parts = append(parts, fmt.Sprintf("%s%s[white] %s%s", colors[identifier], tview.Escape(identifier), value, pointTime))
+		compact = append(compact, colors[identifier]+"●[white] "+value)
+	}
+
+	readout := strings.Join(parts, "  ")
+	if tview.TaggedStringWidth(readout) <= maxWidth {
+		return readout
+	}
+	return truncateTagged(strings.Join(compact, " "), maxWidth)
+}
+
+// cursorStatusNames 光标读数中数据点状态的显示名称和颜色
+var cursorStatusNames = map[core.PointStatus]string{
+	core.PointSuccess:      "[green]成功[white]",
+	core.PointTimeout:      "[red]超时[white]",
+	core.PointInterpolated: "[gray]插值[white]",
+	core.PointLate:         "[yellow]迟到[white]",
+}
+
+// formatCursorPoint 格式化光标处数据点的延迟和状态，超时的延迟显示为t/o
+func formatCursorPoint(point core.DataPoint) string {
+	value := formatLatency(point.Value)
+	if math.IsNaN(point.Value) || math.IsInf(point.Value, 0) {
+		value = "[red]t/o[white]"
+	}
+	return value + " " + cursorStatusNames[point.Status]
+}
@@ -14,8 +14,22 @@ func (t *TUI) updateStatsWithTime(result core.PingResult) {
 	t.statsMu.Lock()
 	defer t.statsMu.Unlock()
 
+	// 已移除目标残留在数据通道中的结果直接丢弃
+	if t.isStaleResult(result.Identifier, result.SendTime) {
+		return
+	}
+
 	// 根据result.Identifier获取或创建core.Stats实例
 	stats := t.getOrCreateStats(result.Identifier)
+	counted := t.countsTowardStats(result.Identifier, result.SendTime)
+
+	// 重复和迟到的回复不对应新的探测包，只计入异常计数
+	if result.Kind != core.ReplyNormal {
+		t.recordExtraReply(stats, result, counted)
+		t.updateSummary(stats)
+		return
+	}
+	t.resultRate.add(time.Now())
 
 	// 创建数据点
 	dataPoint := core.DataPoint{
@@ -31,12 +45,24 @@ func (t *TUI) updateStatsWithTime(result core.PingResult) {
 
 	// 插入数据点（按时间戳排序插入）
 	t.insertDataPointByTime(stats, dataPoint)
+	t.addToRollup(stats, dataPoint.Timestamp, result.Latency)
+	t.dequeueOutOfWindow(stats)
+
+	// 重置统计之前发出的探测只更新图表
+	if !counted {
+		t.updateSummary(stats)
+		return
+	}
 
 	// 更新全局统计
+	if result.OutOfOrder {
+		stats.Reordered++
+	}
 	stats.PacketsSent++
 	if !math.IsNaN(result.Latency) {
 		stats.PacketsRecv++
 		t.updateWelfordAccumulator(stats, result.Latency)
+		stats.distribution.add(result.Latency)
 
 		// 更新最大最小值
 		if result.Latency < stats.MinLatency {
@@ -47,15 +73,79 @@ func (t *TUI) updateStatsWithTime(result core.PingResult) {
 		}
 	}
 
-	// 维护历史缓冲区大小
-	t.dequeueOutOfWindow(stats)
-
 	// 更新汇总信息
 	t.updateSummary(stats)
 }
 
+// updateHealth 根据数据源事件更新目标健康状态
+func (t *TUI) updateHealth(event core.SourceEvent) {
+	if event.Identifier == "" {
+		return
+	}
+
+	t.statsMu.Lock()
+	defer t.statsMu.Unlock()
+
+	if t.isStaleResult(event.Identifier, event.Time) {
+		return
+	}
+	stats := t.getOrCreateStats(event.Identifier)
+	stats.Health = event.Health
+	t.updateSummary(stats)
+}
+
+// recordExtraReply 记录重复、迟到等附加回复
+// counted为false表示回复对应重置统计之前发出的探测，不计入异常计数
+func (t *TUI) recordExtraReply(stats *targetStats, result core.PingResult, counted bool) {
+	switch result.Kind {
+	case core.ReplyDuplicate:
+		if counted {
+			stats.Duplicates++
+		}
+	case core.ReplyLate:
+		t.recordLateReply(stats, result, counted)
+	}
+	if counted && result.OutOfOrder {
+		stats.Reordered++
+	}
+}
+
+// recordLateReply 记录迟到回复
+// 将对应的超时数据点改为迟到状态并填入真实延迟，延迟同样计入全局统计；
+// 对应的超时已在重置统计时清零时，只更新图表，避免迟到数超过超时数使丢包率为负
+func (t *TUI) recordLateReply(stats *targetStats, result core.PingResult, counted bool) {
+	if counted {
+		stats.LateReplies++
+	}
+
+	for i := len(stats.History) - 1; i >= 0; i-- {
+		point := &stats.History[i]
+		if point.Status == core.PointTimeout && point.Timestamp.Equal(result.SendTime) {
+			point.Status = core.PointLate
+			point.Value = result.Latency
+			break
+		}
+	}
+
+	if math.IsNaN(result.Latency) {
+		return
+	}
+	t.reviseRollup(stats, result.SendTime, result.Latency)
+	if !counted {
+		return
+	}
+	t.updateWelfordAccumulator(stats, result.Latency)
+	stats.distribution.add(result.Latency)
+	if result.Latency < stats.MinLatency {
+		stats.MinLatency = result.Latency
+	}
+	if result.Latency > stats.MaxLatency {
+		stats.MaxLatency = result.Latency
+	}
+}
+
 // insertDataPointByTime 按时间戳插入数据点到历史记录中
-func (t *TUI) insertDataPointByTime(stats *core.Stats, newPoint core.DataPoint) {
+func (t *TUI) insertDataPointByTime(stats *targetStats, newPoint core.DataPoint) {
 	// 如果历史记录为空，直接插入
 	if len(stats.History) == 0 {
 		stats.History = append(stats.History, newPoint)
@@ -77,19 +167,20 @@ func (t *TUI) insertDataPointByTime(stats *core.Stats, newPoint core.DataPoint)
 }
 
 // interpolateFromTimeout 从超时状态插值到正常状态
-func (t *TUI) interpolateFromTimeout(stats *core.Stats, lastPoint, newPoint core.DataPoint) {
+// 插值步长使用该目标自身的探测间隔，以支持不同目标使用不同的间隔
+func (t *TUI) interpolateFromTimeout(stats *targetStats, lastPoint, newPoint core.DataPoint) {
 	timeDiff := newPoint.Timestamp.Sub(lastPoint.Timestamp)
-	expectedInterval := t.tuiConfig.TimeGridInterval
+	expectedInterval := t.targetInterval(stats.Identifier)
 	steps := int(timeDiff / expectedInterval)
 
-	t.interpolateFromTimeoutToNormal(stats, lastPoint, newPoint, steps)
+	t.interpolateFromTimeoutToNormal(stats, lastPoint, newPoint, steps, expectedInterval)
 	
 }
 
 // fillWithNaN 用NaN填充时间间隔
-func (t *TUI) fillWithNaN(stats *core.Stats, lastPoint, newPoint core.DataPoint) {
+func (t *TUI) fillWithNaN(stats *targetStats, lastPoint, newPoint core.DataPoint) {
 	timeDiff := newPoint.Timestamp.Sub(lastPoint.Timestamp)
-	expectedInterval := t.tuiConfig.TimeGridInterval
+	expectedInterval := t.targetInterval(stats.Identifier)
 	steps := int(timeDiff / expectedInterval)
 
 	for i := 1; i < steps; i++ {
@@ -104,8 +195,7 @@ func (t *TUI) fillWithNaN(stats *core.Stats, lastPoint, newPoint core.DataPoint)
 }
 
 // interpolateFromTimeoutToNormal 从超时状态插值到正常状态
-func (t *TUI) interpolateFromTimeoutToNormal(stats *core.Stats, lastPoint, newPoint core.DataPoint, steps int) {
-	stepDuration := t.tuiConfig.TimeGridInterval
+func (t *TUI) interpolateFromTimeoutToNormal(stats *targetStats, lastPoint, newPoint core.DataPoint, steps int, stepDuration time.Duration) {
 	ceilingValue := t.getCurrentCeilingValue(stats)
 	valueDiff := (ceilingValue - newPoint.Value) / float64(steps)
 
@@ -124,7 +214,7 @@ func (t *TUI) interpolateFromTimeoutToNormal(stats *core.Stats, lastPoint, newPo
 }
 
 // getCurrentCeilingValue 获取当前的天花板值
-func (t *TUI) getCurrentCeilingValue(stats *core.Stats) float64 {
+func (t *TUI) getCurrentCeilingValue(stats *targetStats) float64 {
 	// 从历史数据中计算动态天花板
 	var maxValue float64 = 0
 	for _, point := range stats.History {
@@ -137,11 +227,28 @@ func (t *TUI) getCurrentCeilingValue(stats *core.Stats) float64 {
 	return maxValue
 }
 
-// dequeueOutOfWindow 移除窗口外的数据点
-func (t *TUI) dequeueOutOfWindow(stats *core.Stats) {
-	if len(stats.History) > t.tuiConfig.MaxHistorySize {
+// dequeueOutOfWindow 移除超出保留范围的数据点
+func (t *TUI) dequeueOutOfWindow(stats *targetStats) {
+	if len(stats.History) == 0 {
+		return
+	}
+
+	// 移除早于保留时长的数据点
+	cutoff := stats.History[len(stats.History)-1].Timestamp.Add(-t.retention())
+	drop := 0
+	for drop < len(stats.History) && stats.History[drop].Timestamp.Before(cutoff) {
+		drop++
+	}
+
+	// 同时限制数据点数量，防止时间戳异常时无限增长
+	capacity := t.historyCapacity(stats.Identifier)
+	if len(stats.History)-drop > capacity {
+		drop = len(stats.History) - capacity
+	}
+
+	if drop > 0 {
 		// 移除最老的数据点
-		stats.History = stats.History[len(stats.History)-t.tuiConfig.MaxHistorySize:]
+		stats.History = stats.History[drop:]
 	}
 }
 
@@ -157,12 +264,14 @@ func (t *TUI) maintainTimeGrid() {
 }
 
 // processPendingTimeouts 处理待定的超时
-func (t *TUI) processPendingTimeouts(stats *core.Stats, now time.Time) {
+func (t *TUI) processPendingTimeouts(stats *targetStats, now time.Time) {
+	threshold := t.targetTimeoutThreshold(stats.Identifier)
+
 	// 检查历史记录中是否有pending状态的点需要转换为超时
 	for i := len(stats.History) - 1; i >= 0; i-- {
 		point := &stats.History[i]
 		if point.Status == core.PointPending {
-			if now.Sub(point.Timestamp) > t.timeoutThreshold {
+			if now.Sub(point.Timestamp) > threshold {
 				point.Status = core.PointTimeout
 				point.Value = math.NaN()
 			}
@@ -171,7 +280,7 @@ func (t *TUI) processPendingTimeouts(stats *core.Stats, now time.Time) {
 }
 
 // updateWelfordAccumulator 使用Welford在线算法更新统计累加器
-func (t *TUI) updateWelfordAccumulator(stats *core.Stats, newValue float64) {
+func (t *TUI) updateWelfordAccumulator(stats *targetStats, newValue float64) {
 	stats.WelfordCount++
 	delta := newValue - stats.WelfordMean
 	stats.WelfordMean += delta / float64(stats.WelfordCount)
@@ -180,25 +289,22 @@ func (t *TUI) updateWelfordAccumulator(stats *core.Stats, newValue float64) {
 }
 
 // updateSummary 更新汇总统计信息
-func (t *TUI) updateSummary(stats *core.Stats) {
+func (t *TUI) updateSummary(stats *targetStats) {
 	summary := make(map[string]string)
 
-	// 超时次数
+	// 超时次数（包含之后迟到的回复）
 	timeouts := stats.PacketsSent - stats.PacketsRecv
 	summary["t/o"] = fmt.Sprintf("%d", timeouts)
 
-	// 丢包率
-	var lossRate float64
-	if stats.PacketsSent > 0 {
-		lossRate = float64(stats.PacketsSent-stats.PacketsRecv) / float64(stats.PacketsSent) * 100
-	}
-	summary["丢包率"] = fmt.Sprintf("%.1f%%", lossRate)
+	// 丢包率：迟到的回复不计为丢包
+	summary["丢包率"] = fmt.Sprintf("%.1f%%", lossRate(stats))
+	summary["迟到"] = fmt.Sprintf("%d", stats.LateReplies)
 
 	// 发送/接收合并显示
 	summary["发送/接收"] = fmt.Sprintf("%d/%d", stats.PacketsSent, stats.PacketsRecv)
 
 	// 延迟统计
-	if stats.PacketsRecv > 0 {
+	if stats.WelfordCount > 0 {
 		summary["平均延迟"] = formatLatency(stats.WelfordMean)
 		summary["最小延迟"] = formatLatency(stats.MinLatency)
 		summary["最大延迟"] = formatLatency(stats.MaxLatency)
@@ -208,5 +314,39 @@ func (t *TUI) updateSummary(stats *core.Stats) {
 		summary["最大延迟"] = "N/A"
 	}
 
+	// 最近延迟、标准差和分位数
+	summary["最近延迟"] = formatOptionalLatency(lastLatency(stats))
+	summary["标准差"] = formatOptionalLatency(stdDev(stats))
+	for _, q := range []struct {
+		key   string
+		value float64
+	}{{"P50", 0.50}, {"P90", 0.90}, {"P95", 0.95}, {"P99", 0.99}} {
+		summary[q.key] = formatOptionalLatency(quantileColumn(q.value)(stats))
+	}
+
+	// 健康状态
+	summary["状态"] = stats.Health.String()
+
+	// 异常回复计数
+	summary["重复"] = fmt.Sprintf("%d", stats.Duplicates)
+	summary["乱序"] = fmt.Sprintf("%d", stats.Reordered)
+
 	stats.Summary = summary
 }
+
+// formatOptionalLatency 格式化可能没有数据的延迟，NaN显示为N/A
+func formatOptionalLatency(latency float64) string {
+	if math.IsNaN(latency) {
+		return "N/A"
+	}
+	return formatLatency(latency)
+}
+
+// lossRate 计算丢包率（百分比），迟到的回复不计为丢包
+func lossRate(stats *targetStats) float64 {
+	if stats.PacketsSent == 0 {
+		return 0
+	}
+	lost := stats.PacketsSent - stats.PacketsRecv - stats.LateReplies
+	return float64(lost) / float64(stats.PacketsSent) * 100
+}
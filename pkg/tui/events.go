@@ -0,0 +1,334 @@
+// Package tui 事件日志模块
+// 从ping结果流中检测目标中断、恢复和丢包突发，连同数据源的地址变化和错误事件一起记录
+package tui
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Kevin-Rudy/goping/pkg/core"
+	"github.com/rivo/tview"
+)
+
+const (
+	eventDownThreshold  = 3  // 连续超时多少次后记录目标中断
+	lossBurstWindow     = 10 // 判定丢包突发时统计的最近探测次数
+	lossBurstStartCount = 3  // 最近探测中丢失多少次时记录丢包突发开始
+	lossBurstEndCount   = 1  // 最近探测中丢失不超过多少次时记录丢包突发结束
+	eventLogHeight      = 8  // 事件日志面板的高度（含标题行）
+)
+
+// eventKind 事件类型
+type eventKind int
+
+const (
+	eventDown           eventKind = iota // 目标中断
+	eventRecovered                       // 目标恢复
+	eventLossBurstStart                  // 丢包突发开始
+	eventLossBurstEnd                    // 丢包突发结束
+	eventAddress                         // 解析地址变化
+	eventError                           // 数据源错误
+	eventMarker                          // 用户添加的时间轴标记
+	eventReset                           // 用户重置统计
+)
+
+// color 返回事件类型在日志面板中的颜色标签
+func (k eventKind) color() string {
+	switch k {
+	case eventDown, eventError:
+		return "[red]"
+	case eventLossBurstStart:
+		return "[orange]"
+	case eventRecovered, eventLossBurstEnd:
+		return "[green]"
+	case eventReset:
+		return "[aqua]"
+	default:
+		return "[yellow]"
+	}
+}
+
+// logEvent 事件日志中的一条记录
+type logEvent struct {
+	Time       time.Time // 事件发生时间
+	Identifier string    // 相关目标，为空表示数据源整体的事件
+	Kind       eventKind // 事件类型
+	Detail     string    // 事件描述
+}
+
+// target 返回事件的目标名称
+func (e logEvent) target() string {
+	if e.Kind == eventMarker {
+		return "标记"
+	}
+	if e.Identifier == "" {
+		return "数据源"
+	}
+	return e.Identifier
+}
+
+// String 返回写入事件日志文件的文本
+func (e logEvent) String() string {
+	return fmt.Sprintf("%s %s %s", e.Time.Format("2006-01-02 15:04:05.000"), e.target(), e.Detail)
+}
+
+// targetEventState 单个目标的事件检测状态
+type targetEventState struct {
+	consecutiveLoss int       // 连续超时次数
+	firstLoss       time.Time // 本轮连续超时中第一个探测的发送时间
+	down            bool      // 是否处于中断状态
+	recent          []bool    // 最近探测是否丢失，最多lossBurstWindow个
+	burst           bool      // 是否处于丢包突发中
+	burstStart      time.Time // 丢包突发开始时间
+	address         string    // 目标最近一次解析到的地址
+}
+
+// getEventState 获取或创建目标的事件检测状态，调用方需持有eventsMu
+func (t *TUI) getEventState(identifier string) *targetEventState {
+	state, exists := t.eventStates[identifier]
+	if !exists {
+		state = &targetEventState{}
+		t.eventStates[identifier] = state
+	}
+	return state
+}
+
+// detectEvents 根据一次ping结果检测目标状态变化
+// 重复和迟到的回复不反映目标当前状态，不参与检测
+func (t *TUI) detectEvents(result core.PingResult) []logEvent {
+	if result.Kind != core.ReplyNormal {
+		return nil
+	}
+
+	t.statsMu.RLock()
+	stale := t.isStaleResult(result.Identifier, result.SendTime)
+	t.statsMu.RUnlock()
+	if stale {
+		return nil
+	}
+
+	t.eventsMu.Lock()
+	defer t.eventsMu.Unlock()
+
+	state := t.getEventState(result.Identifier)
+	newEvent := func(kind eventKind, detail string) logEvent {
+		return logEvent{Time: result.SendTime, Identifier: result.Identifier, Kind: kind, Detail: detail}
+	}
+
+	lost := math.IsNaN(result.Latency)
+	var events []logEvent
+
+	if lost {
+		if state.consecutiveLoss == 0 {
+			state.firstLoss = result.SendTime
+		}
+		state.consecutiveLoss++
+		if !state.down && state.consecutiveLoss >= eventDownThreshold {
+			state.down = true
+			events = append(events, newEvent(eventDown, fmt.Sprintf("目标中断，连续%d次超时", state.consecutiveLoss)))
+		}
+	} else {
+		if state.down {
+			state.down = false
+			// 中断期间的丢包已记录为中断，不再计入丢包突发
+			state.recent = state.recent[:0]
+			events = append(events, newEvent(eventRecovered,
+				fmt.Sprintf("目标恢复，中断 %s", formatEventDuration(result.SendTime.Sub(state.firstLoss)))))
+		}
+		state.consecutiveLoss = 0
+	}
+
+	if state.down {
+		return events
+	}
+
+	state.recent = append(state.recent, lost)
+	if len(state.recent) > lossBurstWindow {
+		state.recent = state.recent[1:]
+	}
+	lostCount := 0
+	for _, l := range state.recent {
+		if l {
+			lostCount++
+		}
+	}
+
+	if !state.burst && lostCount >= lossBurstStartCount {
+		state.burst = true
+		state.burstStart = result.SendTime
+		events = append(events, newEvent(eventLossBurstStart,
+			fmt.Sprintf("丢包突发开始，最近%d次探测丢失%d次", len(state.recent), lostCount)))
+	} else if state.burst && lostCount <= lossBurstEndCount {
+		state.burst = false
+		events = append(events, newEvent(eventLossBurstEnd,
+			fmt.Sprintf("丢包突发结束，持续 %s", formatEventDuration(result.SendTime.Sub(state.burstStart)))))
+	}
+
+	return events
+}
+
+// sourceEvents 将数据源事件转换为事件日志记录
+// 健康状态变化由detectEvents从ping结果中检测，这里只记录地址变化和错误
+func (t *TUI) sourceEvents(event core.SourceEvent) []logEvent {
+	var events []logEvent
+
+	if event.Address != "" && event.Identifier != "" {
+		t.eventsMu.Lock()
+		state := t.getEventState(event.Identifier)
+		previous := state.address
+		state.address = event.Address
+		t.eventsMu.Unlock()
+
+		detail := fmt.Sprintf("解析地址为 %s", event.Address)
+		if previous != "" {
+			detail = fmt.Sprintf("解析地址变化 %s → %s", previous, event.Address)
+		}
+		events = append(events, logEvent{Time: event.Time, Identifier: event.Identifier, Kind: eventAddress, Detail: detail})
+	}
+
+	if event.Err != nil {
+		events = append(events, logEvent{Time: event.Time, Identifier: event.Identifier, Kind: eventError, Detail: event.Err.Error()})
+	}
+
+	return events
+}
+
+// recordEvents 将事件加入事件日志，并写入事件日志文件
+func (t *TUI) recordEvents(events []logEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	t.eventsMu.Lock()
+	defer t.eventsMu.Unlock()
+
+	t.events = append(t.events, events...)
+	if overflow := len(t.events) - t.tuiConfig.EventLogSize; overflow > 0 {
+		t.events = append(t.events[:0], t.events[overflow:]...)
+	}
+
+	if t.eventFile == nil {
+		return
+	}
+	for _, event := range events {
+		if _, err := fmt.Fprintln(t.eventFile, event); err != nil {
+			// 写入失败后不再写文件，面板中的记录不受影响
+			t.eventFile.Close()
+			t.eventFile = nil
+			t.events = append(t.events, logEvent{Time: time.Now(), Kind: eventError, Detail: fmt.Sprintf("写入事件日志文件失败: %v", err)})
+			return
+		}
+	}
+}
+
+// openEventLog 按配置打开事件日志文件，以追加方式写入
+func (t *TUI) openEventLog() error {
+	if t.tuiConfig.EventLogFile == "" {
+		return nil
+	}
+	file, err := os.OpenFile(t.tuiConfig.EventLogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	t.eventsMu.Lock()
+	t.eventFile = file
+	t.eventsMu.Unlock()
+	return nil
+}
+
+// closeEventLog 关闭事件日志文件
+func (t *TUI) closeEventLog() {
+	t.eventsMu.Lock()
+	defer t.eventsMu.Unlock()
+
+	if t.eventFile != nil {
+		t.eventFile.Close()
+		t.eventFile = nil
+	}
+}
+
+// forgetEvents 丢弃目标的事件检测状态，目标被移除时调用
+// 已记录的事件保留在日志中
+func (t *TUI) forgetEvents(identifier string) {
+	t.eventsMu.Lock()
+	defer t.eventsMu.Unlock()
+
+	delete(t.eventStates, identifier)
+}
+
+// toggleEventLog 显示或隐藏事件日志面板
+func (t *TUI) toggleEventLog() {
+	t.eventLogShown = !t.eventLogShown
+	t.eventScroll = 0
+}
+
+// scrollEventLog 滚动事件日志，正数向更早的事件滚动，滚动到底部时恢复跟随最新事件
+func (t *TUI) scrollEventLog(lines int) {
+	t.eventScroll += lines
+	if t.eventScroll < 0 {
+		t.eventScroll = 0
+	}
+}
+
+// addEventLogPane 显示事件日志面板时将其加入主布局
+func (t *TUI) addEventLogPane() {
+	if t.eventLogShown && t.eventView != nil {
+		t.flex.AddItem(t.eventView, eventLogHeight, 0, false)
+	}
+}
+
+// drawEventLog 绘制事件日志面板，最新的事件在最下方
+// 调用者需持有statsMu（目标颜色由statsMu保护）
+func (t *TUI) drawEventLog(height int) string {
+	t.eventsMu.Lock()
+	defer t.eventsMu.Unlock()
+
+	rows := height - 1 // 标题行
+	if rows < 1 {
+		rows = 1
+	}
+
+	// 滚动偏移不超过最早的一页
+	maxScroll := len(t.events) - rows
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+	if t.eventScroll > maxScroll {
+		t.eventScroll = maxScroll
+	}
+
+	end := len(t.events) - t.eventScroll
+	start := end - rows
+	if start < 0 {
+		start = 0
+	}
+
+	title := fmt.Sprintf("[yellow]事件日志[white] [gray]共%d条", len(t.events))
+	if t.eventScroll > 0 {
+		title += fmt.Sprintf("，已向前滚动%d条", t.eventScroll)
+	}
+	title += " PgUp/PgDn 滚动[white]"
+
+	lines := []string{title}
+	if len(t.events) == 0 {
+		lines = append(lines, "[gray]暂无事件[white]")
+	}
+	for _, event := range t.events[start:end] {
+		lines = append(lines, fmt.Sprintf("[gray]%s[white] %s%s[white] %s%s[white]",
+			event.Time.Format("15:04:05"), t.getTargetColor(event.Identifier), tview.Escape(event.target()),
+			event.Kind.color(), tview.Escape(event.Detail)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatEventDuration 格式化事件中的持续时间
+func formatEventDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
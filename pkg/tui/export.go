@@ -0,0 +1,131 @@
+// Package tui 数据导出模块
+package tui
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"time"
+
+	"github.com/Kevin-Rudy/goping/pkg/core"
+)
+
+// exportData 导出文件的顶层结构
+type exportData struct {
+	ExportedAt time.Time      `json:"exported_at"`
+	StartedAt  time.Time      `json:"started_at"`
+	Targets    []exportTarget `json:"targets"`
+	Events     []exportEvent  `json:"events"`
+	Markers    []exportMarker `json:"markers"`
+}
+
+// exportTarget 单个目标的统计数据和历史
+// 延迟单位为毫秒，没有样本时为null
+type exportTarget struct {
+	Identifier  string        `json:"identifier"`
+	Health      string        `json:"health"`
+	Sent        int           `json:"sent"`
+	Received    int           `json:"received"`
+	LossPercent float64       `json:"loss_percent"`
+	AvgMs       *float64      `json:"avg_ms"`
+	MinMs       *float64      `json:"min_ms"`
+	MaxMs       *float64      `json:"max_ms"`
+	Late        int           `json:"late"`
+	Duplicates  int           `json:"duplicates"`
+	Reordered   int           `json:"reordered"`
+	History     []exportPoint `json:"history"`
+}
+
+// exportPoint 历史中的一个数据点，超时的延迟为null
+type exportPoint struct {
+	Time      time.Time `json:"time"`
+	LatencyMs *float64  `json:"latency_ms"`
+	Status    string    `json:"status"`
+}
+
+// exportEvent 事件日志中的一条记录
+type exportEvent struct {
+	Time   time.Time `json:"time"`
+	Target string    `json:"target,omitempty"`
+	Detail string    `json:"detail"`
+}
+
+// exportMarker 时间轴上的一个标记
+type exportMarker struct {
+	Time  time.Time `json:"time"`
+	Label string    `json:"label"`
+}
+
+// pointStatusNames 数据点状态在导出文件中的名称
+var pointStatusNames = map[core.PointStatus]string{
+	core.PointPending:      "pending",
+	core.PointSuccess:      "success",
+	core.PointTimeout:      "timeout",
+	core.PointInterpolated: "interpolated",
+	core.PointLate:         "late",
+}
+
+// exportJSON 将所有目标的统计数据、保留的历史、事件日志和时间轴标记导出为JSON文件
+// 插值点不是真实的探测结果，不导出
+func (t *TUI) exportJSON(path string) error {
+	data := exportData{ExportedAt: time.Now(), StartedAt: t.startTime}
+
+	t.statsMu.RLock()
+	for _, identifier := range t.targets {
+		stats, exists := t.statsData[identifier]
+		if !exists {
+			continue
+		}
+		target := exportTarget{
+			Identifier:  identifier,
+			Health:      stats.Health.String(),
+			Sent:        stats.PacketsSent,
+			Received:    stats.PacketsRecv,
+			LossPercent: lossRate(stats),
+			MinMs:       jsonLatency(stats.MinLatency),
+			MaxMs:       jsonLatency(stats.MaxLatency),
+			Late:        stats.LateReplies,
+			Duplicates:  stats.Duplicates,
+			Reordered:   stats.Reordered,
+			History:     make([]exportPoint, 0, len(stats.History)),
+		}
+		if stats.WelfordCount > 0 {
+			target.AvgMs = jsonLatency(stats.WelfordMean)
+		}
+		for _, point := range stats.History {
+			if point.Status == core.PointInterpolated {
+				continue
+			}
+			target.History = append(target.History, exportPoint{
+				Time:      point.Timestamp,
+				LatencyMs: jsonLatency(point.Value),
+				Status:    pointStatusNames[point.Status],
+			})
+		}
+		data.Targets = append(data.Targets, target)
+	}
+	for _, marker := range t.markers {
+		data.Markers = append(data.Markers, exportMarker{Time: marker.Time, Label: marker.Label})
+	}
+	t.statsMu.RUnlock()
+
+	t.eventsMu.Lock()
+	for _, event := range t.events {
+		data.Events = append(data.Events, exportEvent{Time: event.Time, Target: event.Identifier, Detail: event.Detail})
+	}
+	t.eventsMu.Unlock()
+
+	encoded, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, encoded, 0644)
+}
+
+// jsonLatency 将延迟转换为可以写入JSON的值，NaN和无穷大转换为null
+func jsonLatency(value float64) *float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil
+	}
+	return &value
+}
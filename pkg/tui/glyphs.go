@@ -0,0 +1,149 @@
+// Package tui 图表字符集模块
+// 折线图先在子像素画布上绘制，再按字符集将每个字符格中的子像素合成为一个字符；
+// 不同字符集只是子像素的划分方式不同，时间到X坐标、延迟到Y坐标的映射完全相同
+package tui
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// chartGlyphs 折线图使用的字符集
+type chartGlyphs struct {
+	name       string
+	cellWidth  int               // 每个字符格的子像素列数
+	cellHeight int               // 每个字符格的子像素行数
+	dots       [][]int           // dots[subY][subX] 为子像素在字符格掩码中的位
+	glyph      func(int) rune    // 将字符格的子像素掩码合成为字符
+	fill       string            // 带状图中最小到最大延迟范围的填充字符
+	lossMark   string            // 带状图中全部丢失的时间桶在顶部的标记
+	replacer   *strings.Replacer // 将图表中的其他非ASCII字符替换为ASCII字符，为nil表示不替换
+}
+
+// rendererNames 可选的图表字符集，auto表示根据终端环境自动选择
+var rendererNames = []string{"auto", "braille", "block", "ascii"}
+
+// brailleGlyphs 盲文字符：每格2x4个点，分辨率最高
+var brailleGlyphs = &chartGlyphs{
+	name:       "braille",
+	cellWidth:  2,
+	cellHeight: 4,
+	dots: [][]int{
+		{0b00000001, 0b00001000}, // (y:0, x:0), (y:0, x:1)
+		{0b00000010, 0b00010000}, // (y:1, x:0), (y:1, x:1)
+		{0b00000100, 0b00100000}, // (y:2, x:0), (y:2, x:1)
+		{0b01000000, 0b10000000}, // (y:3, x:0), (y:3, x:1)
+	},
+	glyph:    func(mask int) rune { return rune(0x2800 + mask) },
+	fill:     "░",
+	lossMark: "▀",
+}
+
+// blockGlyphs 半高方块字符：每格1x2个点，大多数控制台字体都包含这些字符
+var blockGlyphs = &chartGlyphs{
+	name:       "block",
+	cellWidth:  1,
+	cellHeight: 2,
+	dots:       [][]int{{0b01}, {0b10}},
+	glyph:      func(mask int) rune { return []rune(" ▀▄█")[mask] },
+	fill:       "░",
+	lossMark:   "▀",
+}
+
+// asciiGlyphs 纯ASCII字符：每格1x3个点，分别以'、-、_表示上中下，纵向跨越多个点时显示为|
+var asciiGlyphs = &chartGlyphs{
+	name:       "ascii",
+	cellWidth:  1,
+	cellHeight: 3,
+	dots:       [][]int{{0b001}, {0b010}, {0b100}},
+	glyph:      func(mask int) rune { return []rune(" '-|_|||")[mask] },
+	fill:       ":",
+	lossMark:   "^",
+	replacer: strings.NewReplacer(
+		"│", "|", "└", "+", "┬", "+", "─", "-", "┊", ":", "╌", "-", "┈", ".", "·", ".", "×", "x",
+		"░", ".", "▒", ":", "▓", "#", "█", "#", "▀", "^",
+		"▁", "_", "▂", ".", "▃", "-", "▄", "=", "▅", "+", "▆", "*", "▇", "%",
+		"▼", "v", "▲", "^", "µ", "u",
+	),
+}
+
+// glyphsByName 按名称查找字符集
+func glyphsByName(name string) (*chartGlyphs, bool) {
+	switch name {
+	case "braille":
+		return brailleGlyphs, true
+	case "block":
+		return blockGlyphs, true
+	case "ascii":
+		return asciiGlyphs, true
+	}
+	return nil, false
+}
+
+// RendererNames 返回所有可选的图表字符集名称
+func RendererNames() []string {
+	return append([]string(nil), rendererNames...)
+}
+
+// resolveGlyphs 返回配置的字符集，auto时根据终端环境自动选择
+func resolveGlyphs(name string, getenv func(string) string) *chartGlyphs {
+	if glyphs, ok := glyphsByName(name); ok {
+		return glyphs
+	}
+	glyphs, _ := glyphsByName(detectRenderer(getenv, runtime.GOOS))
+	return glyphs
+}
+
+// detectRenderer 根据终端环境推测可以显示的字符集
+// 非UTF-8的字符集只能显示ASCII；Linux虚拟控制台和传统Windows控制台的字体通常没有盲文字符
+func detectRenderer(getenv func(string) string, goos string) string {
+	for _, key := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := getenv(key); value != "" {
+			value = strings.ToLower(value)
+			if !strings.Contains(value, "utf-8") && !strings.Contains(value, "utf8") {
+				return "ascii"
+			}
+			break
+		}
+	}
+	if getenv("TERM") == "linux" {
+		return "block"
+	}
+	if goos == "windows" && getenv("WT_SESSION") == "" {
+		return "block"
+	}
+	return "braille"
+}
+
+// rowMask 返回字符格中第subY行所有子像素的掩码，用于绘制水平短线
+func (g *chartGlyphs) rowMask(subY int) int {
+	mask := 0
+	for _, dot := range g.dots[subY] {
+		mask |= dot
+	}
+	return mask
+}
+
+// setRenderer 切换图表字符集
+func (t *TUI) setRenderer(name string) error {
+	if name == "auto" {
+		t.glyphs = resolveGlyphs(name, os.Getenv)
+		return nil
+	}
+	glyphs, ok := glyphsByName(name)
+	if !ok {
+		return fmt.Errorf("未知的图表字符集 '%s'，可选: %s", name, strings.Join(rendererNames, ", "))
+	}
+	t.glyphs = glyphs
+	return nil
+}
+
+// display 返回交给tview显示的文本：替换为主题颜色，纯ASCII字符集下还将图表中的其他非ASCII字符替换为ASCII字符
+func (t *TUI) display(text string) string {
+	if t.glyphs.replacer != nil {
+		text = t.glyphs.replacer.Replace(text)
+	}
+	return t.themed(text)
+}
@@ -0,0 +1,134 @@
+// Package tui 网格视图模块
+// 为每个目标绘制一个小折线图，目标较多时比叠加在一张图上更易于浏览
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Kevin-Rudy/goping/pkg/core"
+	"github.com/rivo/tview"
+)
+
+// toggleGridView 开启或关闭网格视图
+func (t *TUI) toggleGridView() {
+	t.gridView = !t.gridView
+}
+
+// toggleGridSharedScale 切换网格中的小图使用共享还是独立的Y轴刻度
+func (t *TUI) toggleGridSharedScale() {
+	t.gridSharedScale = !t.gridSharedScale
+}
+
+// gridLayout 计算网格的列数和行数，使每个小图尽量大
+// 盲文字符每格横向2个点、纵向4个点，以像素计的较短边作为评分；
+// 任何布局下小图都小于最小尺寸时ok为false
+func gridLayout(count, width, height, minCellWidth, minCellHeight int) (cols, rows int, ok bool) {
+	bestScore := -1
+	for c := 1; c <= count; c++ {
+		r := (count + c - 1) / c
+		cellWidth, cellHeight := width/c, height/r
+		if cellWidth < minCellWidth || cellHeight < minCellHeight {
+			continue
+		}
+		score := min(cellWidth*2, cellHeight*4)
+		if score > bestScore {
+			bestScore, cols, rows = score, c, r
+		}
+	}
+	return cols, rows, bestScore >= 0
+}
+
+// drawGridChart 以网格形式为每个目标绘制一个小折线图
+// 共享刻度时所有小图使用同一Y轴范围，便于横向比较；否则每个小图按自身数据缩放；
+// 每个小图记录自己的绘图区，鼠标悬停在哪个小图上就按哪个小图换算光标位置
+// 调用者需持有statsMu
+func (t *TUI) drawGridChart(identifiers []string, width, height int) string {
+	windowStart, windowEnd := t.getTimeWindow()
+
+	var drawn []string
+	for _, identifier := range identifiers {
+		if stats, exists := t.statsData[identifier]; exists && (len(stats.History) > 0 || len(stats.rollups) > 0) {
+			drawn = append(drawn, identifier)
+		}
+	}
+
+	if len(drawn) == 0 {
+		return "没有数据"
+	}
+
+	// 每个小图额外需要一行标题和一列间隔
+	cols, rows, ok := gridLayout(len(drawn), width, height, t.tuiConfig.MinChartWidth+1, t.tuiConfig.MinChartHeight+1)
+	if !ok {
+		return "终端尺寸过小，无法显示网格视图"
+	}
+	cellWidth, cellHeight := width/cols, height/rows
+
+	// 与单图和多目标图一样按小图的子像素宽度降采样
+	targetDataPoints := make(map[string][]core.DataPoint)
+	titles := make(map[string]string)
+	for _, identifier := range drawn {
+		stats := t.statsData[identifier]
+		targetDataPoints[identifier] = t.chartPoints(stats, windowStart, windowEnd, (cellWidth-1)*t.glyphs.cellWidth)
+		titles[identifier] = fmt.Sprintf("%s %s", stats.Summary["平均延迟"], stats.Summary["丢包率"])
+	}
+
+	var sharedMin, sharedMax float64
+	if t.gridSharedScale {
+		var errMsg string
+		sharedMin, sharedMax, _, errMsg = t.calculateValueRange(targetDataPoints, windowStart, windowEnd)
+		if errMsg != "" {
+			return errMsg
+		}
+	}
+
+	cells := make([][]string, len(drawn))
+	for i, identifier := range drawn {
+		color := t.getTargetColor(identifier)
+		points := map[string][]core.DataPoint{identifier: targetDataPoints[identifier]}
+		colors := map[string]string{identifier: color}
+
+		// 小图记录的绘图区相对小图左上角，换算为相对整个网格的位置（小图上方有一行标题）
+		areas := len(t.plotAreas)
+		var chart string
+		if t.gridSharedScale {
+			chart = t.drawChartWithCursor(points, colors, cellWidth-1, cellHeight-1, windowStart, windowEnd, sharedMin, sharedMax)
+		} else {
+			chart = t.drawChartWithTimestamps(points, colors, cellWidth-1, cellHeight-1)
+		}
+		for j := areas; j < len(t.plotAreas); j++ {
+			t.plotAreas[j].left += (i % cols) * cellWidth
+			t.plotAreas[j].top += (i/cols)*cellHeight + 1
+		}
+
+		title := fmt.Sprintf("%s%s[white]", color, identifier)
+		if detail := fmt.Sprintf(" [gray]%s[white]", titles[identifier]); tview.TaggedStringWidth(title+detail) < cellWidth {
+			title += detail
+		}
+		cells[i] = append([]string{title}, strings.Split(chart, "\n")...)
+	}
+
+	var lines []string
+	for r := 0; r < rows; r++ {
+		for l := 0; l < cellHeight; l++ {
+			var line strings.Builder
+			for c := 0; c < cols; c++ {
+				text := ""
+				if i := r*cols + c; i < len(cells) && l < len(cells[i]) {
+					text = cells[i][l]
+				}
+				line.WriteString(padTagged(text, cellWidth))
+			}
+			lines = append(lines, line.String())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// padTagged 用空格将带颜色标签的文本补齐到指定的显示宽度
+func padTagged(text string, width int) string {
+	if padding := width - tview.TaggedStringWidth(text); padding > 0 {
+		return text + "[white]" + strings.Repeat(" ", padding)
+	}
+	return text + "[white]"
+}
@@ -0,0 +1,221 @@
+// Package tui 延迟热力图渲染模块
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Kevin-Rudy/goping/pkg/core"
+)
+
+// heatmapShades 热力图单元格的浓度字符，从稀疏到密集
+var heatmapShades = []rune{'░', '▒', '▓', '█'}
+
+// heatmapMinStripHeight 每个目标的热力图条带最小行数（标题行、丢包行和至少一个延迟区间）
+const heatmapMinStripHeight = 3
+
+// heatmapGrid 热力图条带中每一列的样本统计
+type heatmapGrid struct {
+	counts [][]int // 每列各延迟区间的样本数
+	lost   []int   // 每列的丢包数
+	totals []int   // 每列的探测总数
+}
+
+// newHeatmapGrid 创建columns列、rows个延迟区间的热力图统计
+func newHeatmapGrid(columns, rows int) *heatmapGrid {
+	grid := &heatmapGrid{
+		counts: make([][]int, columns),
+		lost:   make([]int, columns),
+		totals: make([]int, columns),
+	}
+	for i := range grid.counts {
+		grid.counts[i] = make([]int, rows)
+	}
+	return grid
+}
+
+// drawHeatmap 绘制延迟分布热力图
+// 横轴为时间，纵轴为延迟区间，每个目标占一个条带；单元格的浓度表示该时间段内
+// 落入该延迟区间的样本比例，顶部单独一行显示丢包比例
+// 调用者需持有statsMu
+func (t *TUI) drawHeatmap(identifiers []string, width, height int) string {
+	if sizeErr := t.validateChartSize(width, height); sizeErr != "" {
+		return sizeErr
+	}
+
+	windowStart, windowEnd := t.getTimeWindow()
+
+	// 值范围由原始数据点或聚合桶的最小/最大值决定
+	targetDataPoints := make(map[string][]core.DataPoint)
+	var drawn []string
+	for _, identifier := range identifiers {
+		if stats, exists := t.statsData[identifier]; exists && (len(stats.History) > 0 || len(stats.rollups) > 0) {
+			targetDataPoints[identifier] = t.distributionPoints(stats, windowStart, windowEnd, width)
+			drawn = append(drawn, identifier)
+		}
+	}
+
+	if len(drawn) == 0 {
+		return "没有数据"
+	}
+
+	minVal, maxVal, _, errMsg := t.calculateValueRange(targetDataPoints, windowStart, windowEnd)
+	if errMsg != "" {
+		return errMsg
+	}
+
+	// Y轴标签宽度，至少容纳丢包行的"t/o"标签
+	maxLabelLen := len("t/o")
+	for _, label := range []string{formatLatency(maxVal), formatLatency(minVal)} {
+		if len(label) > maxLabelLen {
+			maxLabelLen = len(label)
+		}
+	}
+	yAxisLabelWidth := maxLabelLen + 2
+
+	chartBodyHeight := height - 2 // 为X轴和时间戳留出2行空间
+	chartWidth := width - yAxisLabelWidth
+	stripHeight := chartBodyHeight / len(drawn)
+	if chartWidth <= 0 || stripHeight < heatmapMinStripHeight {
+		return "可绘制区域过小"
+	}
+
+	var lines []string
+	for _, identifier := range drawn {
+		grid := t.heatmapGrid(t.statsData[identifier], windowStart, windowEnd, minVal, maxVal, chartWidth, stripHeight-2)
+		strip := t.drawHeatmapStrip(identifier, grid, minVal, maxVal, yAxisLabelWidth, chartWidth, stripHeight)
+		lines = append(lines, strip...)
+	}
+	for len(lines) < chartBodyHeight {
+		lines = append(lines, fmt.Sprintf("[gray]%*s │[white]", yAxisLabelWidth-2, ""))
+	}
+
+	lines = append(lines, t.drawTimeAxis(yAxisLabelWidth, chartWidth, windowStart, windowEnd)...)
+
+	return strings.Join(lines, "\n")
+}
+
+// distributionPoints 返回用于确定热力图值范围的数据点
+// 原始历史覆盖窗口时使用全部原始数据点，否则使用聚合历史降采样后的数据点（含每列的最小/最大值）
+func (t *TUI) distributionPoints(stats *targetStats, start, end time.Time, columns int) []core.DataPoint {
+	if t.useRollups(stats, start) {
+		return t.chartPoints(stats, start, end, columns)
+	}
+	return stats.History
+}
+
+// heatmapGrid 统计目标每一列中各延迟区间的样本数
+// 原始历史覆盖窗口时逐个统计原始数据点；否则合并每列的聚合桶，按桶内保留的延迟分布统计，
+// 使粗粒度下的热力图仍反映真实的样本分布
+// 调用者需持有statsMu
+func (t *TUI) heatmapGrid(stats *targetStats, windowStart, windowEnd time.Time, minVal, maxVal float64, chartWidth, rows int) *heatmapGrid {
+	grid := newHeatmapGrid(chartWidth, rows)
+
+	if t.useRollups(stats, windowStart) {
+		for col, bucket := range downsampleRollups(stats.rollups, windowStart, windowEnd, chartWidth) {
+			grid.lost[col] += bucket.lost
+			grid.totals[col] += bucket.lost + bucket.count
+			histogram := bucket.histogram()
+			for index, count := range histogram.counts {
+				if count == 0 {
+					continue
+				}
+				// 直方图桶的代表值限制在聚合桶的实际范围内，减小分桶误差
+				lower, upper := histogram.bucketBounds(index)
+				value := math.Min(math.Max(math.Sqrt(lower*upper), bucket.min), bucket.max)
+				grid.counts[col][t.heatmapRow(value, minVal, maxVal, rows)] += count
+			}
+		}
+		return grid
+	}
+
+	for _, point := range stats.History {
+		if point.Status == core.PointInterpolated || point.Status == core.PointPending {
+			continue
+		}
+		if !point.Timestamp.After(windowStart) || !point.Timestamp.Before(windowEnd) {
+			continue
+		}
+		col := t.timestampToX(point.Timestamp, windowStart, windowEnd, chartWidth)
+		if col < 0 || col >= chartWidth {
+			continue
+		}
+
+		grid.totals[col]++
+		if math.IsNaN(point.Value) || math.IsInf(point.Value, 0) {
+			grid.lost[col]++
+			continue
+		}
+		grid.counts[col][t.heatmapRow(point.Value, minVal, maxVal, rows)]++
+	}
+	return grid
+}
+
+// heatmapRow 返回延迟值所在的延迟区间序号，从低到高
+func (t *TUI) heatmapRow(value, minVal, maxVal float64, rows int) int {
+	row := int(t.normalizeValue(value, minVal, maxVal) * float64(rows))
+	if row < 0 {
+		return 0
+	}
+	if row >= rows {
+		return rows - 1
+	}
+	return row
+}
+
+// drawHeatmapStrip 绘制单个目标的热力图条带
+// 第一行为目标名称，第二行为丢包比例，其余各行从高到低对应延迟区间
+func (t *TUI) drawHeatmapStrip(identifier string, grid *heatmapGrid, minVal, maxVal float64, yAxisLabelWidth, chartWidth, stripHeight int) []string {
+	buckets := stripHeight - 2
+	color := t.getTargetColor(identifier)
+
+	lines := make([]string, 0, stripHeight)
+	lines = append(lines, fmt.Sprintf("[gray]%*s │[white]%s%s[white]", yAxisLabelWidth-2, "", color, identifier))
+
+	// 丢包行
+	var lossLine strings.Builder
+	fmt.Fprintf(&lossLine, "[gray]%*s │", yAxisLabelWidth-2, "t/o")
+	lossLine.WriteString("[red]")
+	for col := 0; col < chartWidth; col++ {
+		lossLine.WriteRune(heatmapShade(grid.lost[col], grid.totals[col]))
+	}
+	lossLine.WriteString("[white]")
+	lines = append(lines, lossLine.String())
+
+	// 延迟区间，从高到低
+	for row := buckets - 1; row >= 0; row-- {
+		label := ""
+		switch row {
+		case buckets - 1:
+			label = formatLatency(maxVal)
+		case 0:
+			label = formatLatency(minVal)
+		}
+
+		var line strings.Builder
+		fmt.Fprintf(&line, "[gray]%*s │", yAxisLabelWidth-2, label)
+		line.WriteString(color)
+		for col := 0; col < chartWidth; col++ {
+			line.WriteRune(heatmapShade(grid.counts[col][row], grid.totals[col]))
+		}
+		line.WriteString("[white]")
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// heatmapShade 根据样本占比返回单元格的浓度字符，没有样本时返回空格
+func heatmapShade(count, total int) rune {
+	if count == 0 || total == 0 {
+		return ' '
+	}
+	level := int(math.Ceil(float64(count)/float64(total)*float64(len(heatmapShades)))) - 1
+	if level < 0 {
+		level = 0
+	} else if level >= len(heatmapShades) {
+		level = len(heatmapShades) - 1
+	}
+	return heatmapShades[level]
+}
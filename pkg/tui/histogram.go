@@ -0,0 +1,266 @@
+// Package tui 延迟分布直方图模块
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/Kevin-Rudy/goping/pkg/core"
+	"github.com/rivo/tview"
+)
+
+// histogramPanelWidth 直方图面板的宽度
+const histogramPanelWidth = 44
+
+// histogramScope 直方图的统计范围
+type histogramScope int
+
+const (
+	histogramScopeAll    histogramScope = iota // 自启动以来的全部样本
+	histogramScopeWindow                       // 当前可见时间窗口内的样本
+	histogramScopeCount                        // 统计范围数量，用于循环切换
+)
+
+// String 返回统计范围的显示名称
+func (s histogramScope) String() string {
+	if s == histogramScopeWindow {
+		return "窗口"
+	}
+	return "全程"
+}
+
+// histogramPercentiles 直方图上标注的分位数
+var histogramPercentiles = []struct {
+	name string
+	q    float64
+}{
+	{"p50", 0.50},
+	{"p90", 0.90},
+	{"p99", 0.99},
+}
+
+// 延迟直方图的分桶参数：从1µs到100s按对数均匀分桶
+const (
+	histogramMinLatency       = 0.001 // 最小桶的下界(ms)
+	histogramDecades          = 8     // 覆盖的数量级个数
+	histogramBucketsPerDecade = 20    // 每个数量级的桶数
+	histogramBuckets          = histogramDecades * histogramBucketsPerDecade
+)
+
+// latencyHistogram 按对数分桶的延迟直方图
+// 以固定内存统计任意长时间的延迟分布，分位数的相对误差约为12%
+type latencyHistogram struct {
+	counts []int // 各桶的样本数
+	total  int   // 样本总数
+}
+
+// newLatencyHistogram 创建一个空的延迟直方图
+func newLatencyHistogram() *latencyHistogram {
+	return &latencyHistogram{
+		counts: make([]int, histogramBuckets),
+	}
+}
+
+// add 将一个延迟样本(ms)计入直方图，NaN或无穷值被忽略
+func (h *latencyHistogram) add(value float64) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return
+	}
+	h.counts[latencyBucketIndex(value)]++
+	h.total++
+}
+
+// latencyBucketIndex 返回延迟值所在的直方图桶序号，超出范围的值计入首尾两个桶
+func latencyBucketIndex(value float64) int {
+	if value <= histogramMinLatency {
+		return 0
+	}
+	index := int(math.Log10(value/histogramMinLatency) * histogramBucketsPerDecade)
+	if index >= histogramBuckets {
+		index = histogramBuckets - 1
+	}
+	return index
+}
+
+// bucketBounds 返回第i个桶的上下界(ms)
+func (h *latencyHistogram) bucketBounds(i int) (lower, upper float64) {
+	lower = histogramMinLatency * math.Pow(10, float64(i)/histogramBucketsPerDecade)
+	upper = histogramMinLatency * math.Pow(10, float64(i+1)/histogramBucketsPerDecade)
+	return lower, upper
+}
+
+// quantile 返回q分位(0~1)的近似延迟，取所在桶上下界的几何平均；没有样本时返回NaN
+func (h *latencyHistogram) quantile(q float64) float64 {
+	if h.total == 0 {
+		return math.NaN()
+	}
+
+	rank := int(math.Ceil(q * float64(h.total)))
+	if rank < 1 {
+		rank = 1
+	}
+	cumulative := 0
+	for i, count := range h.counts {
+		cumulative += count
+		if cumulative >= rank {
+			lower, upper := h.bucketBounds(i)
+			return math.Sqrt(lower * upper)
+		}
+	}
+	lower, upper := h.bucketBounds(len(h.counts) - 1)
+	return math.Sqrt(lower * upper)
+}
+
+// histogramRow 直方图中的一行，合并了若干个相邻的对数桶
+type histogramRow struct {
+	lower, upper float64  // 延迟区间(ms)
+	count        int      // 区间内的样本数
+	markers      []string // 落在该区间内的分位数
+}
+
+// toggleHistogram 显示或隐藏直方图面板
+func (t *TUI) toggleHistogram() {
+	t.histogramShown = !t.histogramShown
+}
+
+// cycleHistogramScope 切换直方图的统计范围
+func (t *TUI) cycleHistogramScope() {
+	t.histogramScope = (t.histogramScope + 1) % histogramScopeCount
+}
+
+// chartArea 返回图表区域：显示直方图时图表和直方图左右并排
+func (t *TUI) chartArea() tview.Primitive {
+	if !t.histogramShown || t.histogramView == nil {
+		return t.chart
+	}
+	area := tview.NewFlex()
+	area.SetDirection(tview.FlexColumn)
+	area.AddItem(t.chart, 0, 1, false)
+	area.AddItem(t.histogramView, histogramPanelWidth, 0, false)
+	return area
+}
+
+// histogramFor 返回目标在当前统计范围内的延迟直方图，调用方需持有statsMu
+func (t *TUI) histogramFor(stats *targetStats) *latencyHistogram {
+	if t.histogramScope == histogramScopeAll {
+		return stats.distribution
+	}
+
+	windowStart, windowEnd := t.getTimeWindow()
+	histogram := newLatencyHistogram()
+	for _, point := range stats.History {
+		if point.Status != core.PointSuccess && point.Status != core.PointLate {
+			continue
+		}
+		if point.Timestamp.After(windowStart) && point.Timestamp.Before(windowEnd) {
+			histogram.add(point.Value)
+		}
+	}
+	return histogram
+}
+
+// histogramRows 将直方图的非空桶范围合并为最多maxRows行，并标注分位数所在的行
+func histogramRows(histogram *latencyHistogram, maxRows int) []histogramRow {
+	first, last := -1, -1
+	for i, count := range histogram.counts {
+		if count > 0 {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 || maxRows <= 0 {
+		return nil
+	}
+
+	perRow := int(math.Ceil(float64(last-first+1) / float64(maxRows)))
+	var rows []histogramRow
+	for i := first; i <= last; i += perRow {
+		j := i + perRow - 1
+		if j > last {
+			j = last
+		}
+		row := histogramRow{}
+		row.lower, _ = histogram.bucketBounds(i)
+		_, row.upper = histogram.bucketBounds(j)
+		for k := i; k <= j; k++ {
+			row.count += histogram.counts[k]
+		}
+		rows = append(rows, row)
+	}
+
+	for _, percentile := range histogramPercentiles {
+		value := histogram.quantile(percentile.q)
+		for i := range rows {
+			if value >= rows[i].lower && value < rows[i].upper {
+				rows[i].markers = append(rows[i].markers, percentile.name)
+				break
+			}
+		}
+	}
+	return rows
+}
+
+// drawHistogram 绘制目标的延迟分布直方图
+// 每行为一个延迟区间（从低到高），条形长度表示样本数，分位数标注在其所在的行尾
+// 调用者需持有statsMu
+func (t *TUI) drawHistogram(identifier string, width, height int) string {
+	if identifier == "" {
+		return "[gray]选择目标以查看延迟分布[white]"
+	}
+
+	stats, exists := t.statsData[identifier]
+	if !exists {
+		return "没有数据"
+	}
+	histogram := t.histogramFor(stats)
+	if histogram.total == 0 {
+		return "没有数据"
+	}
+
+	// 为标题行和分位数汇总行留出2行
+	rows := histogramRows(histogram, height-2)
+	if len(rows) == 0 || width < 20 {
+		return "可绘制区域过小"
+	}
+
+	labelWidth, maxCount := 0, 0
+	for _, row := range rows {
+		if len(formatLatency(row.lower)) > labelWidth {
+			labelWidth = len(formatLatency(row.lower))
+		}
+		if row.count > maxCount {
+			maxCount = row.count
+		}
+	}
+	markerWidth := len(" p50,p90,p99")
+	barWidth := width - labelWidth - 2 - markerWidth
+	if barWidth < 1 {
+		return "可绘制区域过小"
+	}
+
+	color := t.getTargetColor(identifier)
+	lines := []string{fmt.Sprintf("%s%s[white] [gray]%s n=%d[white]", color, identifier, t.histogramScope, histogram.total)}
+	for _, row := range rows {
+		barLen := row.count * barWidth / maxCount
+		if barLen == 0 && row.count > 0 {
+			barLen = 1
+		}
+		marker := ""
+		if len(row.markers) > 0 {
+			marker = " [yellow]" + strings.Join(row.markers, ",") + "[white]"
+		}
+		lines = append(lines, fmt.Sprintf("[gray]%*s │[white]%s%s[white]%s",
+			labelWidth, formatLatency(row.lower), color, strings.Repeat("█", barLen), marker))
+	}
+
+	var summary []string
+	for _, percentile := range histogramPercentiles {
+		summary = append(summary, fmt.Sprintf("%s %s", percentile.name, formatLatency(histogram.quantile(percentile.q))))
+	}
+	lines = append(lines, "[yellow]"+strings.Join(summary, "  ")+"[white]")
+
+	return strings.Join(lines, "\n")
+}
@@ -49,37 +49,35 @@ func recordNavigationEvent() {
 }
 
 // setupKeyBindings 设置键盘绑定
+// 按键在绑定表中查找对应的操作，见keybindings.go
 func (t *TUI) setupKeyBindings() {
 	t.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		switch event.Key() {
-		case tcell.KeyCtrlC:
-			t.Stop()
-			return nil
-		case tcell.KeyRune:
-			switch event.Rune() {
-			case 'q', 'Q':
-				t.Stop()
-				return nil
-			}
-		case tcell.KeyUp:
-			// 添加频率控制检查
-			if shouldHandleNavigationEvent() {
-				t.navigateUp()
-				recordNavigationEvent()
-			}
+		// 输入框显示时，除Ctrl+C外的按键都交给输入框处理
+		if t.prompt != nil && event.Key() != tcell.KeyCtrlC {
+			return event
+		}
+
+		// 帮助界面显示时，任意键关闭帮助
+		if t.helpShown && event.Key() != tcell.KeyCtrlC {
+			t.hideHelp()
 			return nil
-		case tcell.KeyDown:
-			// 添加频率控制检查
-			if shouldHandleNavigationEvent() {
-				t.navigateDown()
-				recordNavigationEvent()
-			}
+		}
+
+		if t.dispatchKey(event) {
 			return nil
 		}
 		return event
 	})
 }
 
+// throttledNavigate 带频率控制地执行导航操作
+func (t *TUI) throttledNavigate(navigate func()) {
+	if shouldHandleNavigationEvent() {
+		navigate()
+		recordNavigationEvent()
+	}
+}
+
 // navigateUp 向上导航
 func (t *TUI) navigateUp() {
 	if len(t.identifiers) == 0 {
@@ -145,7 +143,7 @@ func (t *TUI) updateSelection() {
 			// 高亮选中的数据行的所有子项
 			for j := 0; j < rowFlex.GetItemCount(); j++ {
 				if textView, ok := rowFlex.GetItem(j).(*tview.TextView); ok {
-					textView.SetBackgroundColor(tcell.ColorDarkCyan)
+					textView.SetBackgroundColor(themeColor(t.theme.Highlight))
 				}
 			}
 		} else {
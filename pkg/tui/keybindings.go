@@ -0,0 +1,368 @@
+// Package tui 按键绑定、帮助界面与命令提示符模块
+// 所有按键和命令定义在同一张表中，按键分发、帮助界面和命令执行都以此表为准
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// helpPage 帮助界面在Pages中的名称
+const helpPage = "help"
+
+// keyBinding 一个按键绑定
+type keyBinding struct {
+	label   string       // 帮助中显示的按键
+	runes   []rune       // 触发的字符按键
+	keys    []tcell.Key  // 触发的特殊按键
+	command string       // 在命令提示符中对应的命令名，为空表示只能通过按键触发
+	desc    string       // 功能说明
+	action  func(t *TUI) // 按键触发的操作，在UI线程中执行
+}
+
+// matches 判断按键事件是否触发该绑定
+func (b keyBinding) matches(event *tcell.EventKey) bool {
+	if event.Key() == tcell.KeyRune {
+		for _, r := range b.runes {
+			if event.Rune() == r {
+				return true
+			}
+		}
+		return false
+	}
+	for _, key := range b.keys {
+		if event.Key() == key {
+			return true
+		}
+	}
+	return false
+}
+
+// command 一个带参数的命令，只能在命令提示符中执行
+type command struct {
+	name    string                                      // 命令名
+	aliases []string                                    // 命令别名
+	usage   string                                      // 帮助中显示的参数格式
+	desc    string                                      // 功能说明
+	run     func(t *TUI, args []string) (string, error) // 执行命令，返回成功时显示的提示消息
+}
+
+// defaultKeyBindings 返回默认的按键绑定表
+func defaultKeyBindings() []keyBinding {
+	return []keyBinding{
+		{label: "↑", keys: []tcell.Key{tcell.KeyUp}, desc: "选择上一个目标，在边界切换到全选模式",
+			action: func(t *TUI) { t.throttledNavigate(t.navigateUp) }},
+		{label: "↓", keys: []tcell.Key{tcell.KeyDown}, desc: "选择下一个目标，在边界切换到全选模式",
+			action: func(t *TUI) { t.throttledNavigate(t.navigateDown) }},
+		{label: "a", runes: []rune{'a'}, desc: "添加目标",
+			action: func(t *TUI) { t.promptAddTarget() }},
+		{label: "d", runes: []rune{'d'}, desc: "移除目标（默认为选中的目标）",
+			action: func(t *TUI) { t.promptRemoveTarget() }},
+		{label: "空格", runes: []rune{' '}, command: "hide", desc: "在全选图表中隐藏/显示选中的目标（继续探测，仍显示在表格中）",
+			action: func(t *TUI) { t.toggleTargetHidden(); t.rebuildUI(); t.updateChart() }},
+		{label: "r", runes: []rune{'r'}, command: "reset", desc: "重置选中目标的统计计数，全选时重置所有目标（图表历史保留）",
+			action: func(t *TUI) { t.resetStats(); t.rebuildUI(); t.updateChart() }},
+		{label: "m", runes: []rune{'m'}, desc: "在当前时刻添加带标签的标记，图表中显示为竖线",
+			action: func(t *TUI) { t.promptAddMarker() }},
+		{label: "p", runes: []rune{'p'}, command: "pause", desc: "暂停/恢复图表（后台继续探测）",
+			action: func(t *TUI) { t.togglePause(); t.updateChart() }},
+		{label: "←", keys: []tcell.Key{tcell.KeyLeft}, desc: "在历史中向前平移，光标模式下左移光标",
+			action: func(t *TUI) { t.panOrMoveCursor(-1) }},
+		{label: "→", keys: []tcell.Key{tcell.KeyRight}, desc: "在历史中向后平移，光标模式下右移光标",
+			action: func(t *TUI) { t.panOrMoveCursor(1) }},
+		{label: "s", runes: []rune{'s'}, desc: "切换表格排序列（依次为各显示列，最后恢复原始顺序）",
+			action: func(t *TUI) { t.cycleSortColumn(); t.rebuildUI(); t.updateChart() }},
+		{label: "S", runes: []rune{'S'}, desc: "反转表格排序方向",
+			action: func(t *TUI) { t.reverseSort(); t.rebuildUI(); t.updateChart() }},
+		{label: "c", runes: []rune{'c'}, command: "cursor", desc: "进入/退出光标模式，查看光标时刻各目标的延迟和状态",
+			action: func(t *TUI) { t.toggleCursorMode(); t.updateChart() }},
+		{label: "Esc", keys: []tcell.Key{tcell.KeyEscape}, desc: "退出光标模式",
+			action: func(t *TUI) { t.exitCursorMode(); t.updateChart() }},
+		{label: "l 或 End", runes: []rune{'l'}, keys: []tcell.Key{tcell.KeyEnd}, command: "live", desc: "回到实时视图",
+			action: func(t *TUI) { t.resumeLive(); t.updateChart() }},
+		{label: "+", runes: []rune{'+', '='}, command: "zoomin", desc: "缩短时间窗口",
+			action: func(t *TUI) { t.zoomIn(); t.updateChart() }},
+		{label: "-", runes: []rune{'-'}, command: "zoomout", desc: "延长时间窗口（默认/1m/5m/1h/24h）",
+			action: func(t *TUI) { t.zoomOut(); t.updateChart() }},
+		{label: "v", runes: []rune{'v'}, command: "view", desc: "切换图表视图（折线图/热力图/带状图）",
+			action: func(t *TUI) { t.cycleChartMode(); t.updateChart() }},
+		{label: "h", runes: []rune{'h'}, command: "histogram", desc: "显示/隐藏选中目标的延迟分布直方图",
+			action: func(t *TUI) { t.toggleHistogram(); t.rebuildUI(); t.updateChart() }},
+		{label: "H", runes: []rune{'H'}, command: "scope", desc: "切换直方图统计范围（全程/可见窗口）",
+			action: func(t *TUI) { t.cycleHistogramScope(); t.updateChart() }},
+		{label: "y", runes: []rune{'y'}, command: "log", desc: "切换Y轴线性/对数刻度",
+			action: func(t *TUI) { t.toggleLogScale(); t.updateChart() }},
+		{label: "t", runes: []rune{'t'}, command: "time", desc: "切换X轴时钟时间/相对时间",
+			action: func(t *TUI) { t.toggleRelativeTime(); t.updateChart() }},
+		{label: "g", runes: []rune{'g'}, command: "grid", desc: "切换网格视图（每个目标一个小图）",
+			action: func(t *TUI) { t.toggleGridView(); t.updateChart() }},
+		{label: "G", runes: []rune{'G'}, command: "gridscale", desc: "网格视图中切换共享/独立Y轴刻度",
+			action: func(t *TUI) { t.toggleGridSharedScale(); t.updateChart() }},
+		{label: "e", runes: []rune{'e'}, command: "events", desc: "显示/隐藏事件日志",
+			action: func(t *TUI) { t.toggleEventLog(); t.rebuildUI(); t.updateChart() }},
+		{label: "PgUp", keys: []tcell.Key{tcell.KeyPgUp}, desc: "事件日志向前滚动",
+			action: func(t *TUI) { t.scrollEventLog(eventLogHeight - 1); t.updateChart() }},
+		{label: "PgDn", keys: []tcell.Key{tcell.KeyPgDn}, desc: "事件日志向后滚动",
+			action: func(t *TUI) { t.scrollEventLog(-(eventLogHeight - 1)); t.updateChart() }},
+		{label: "?", runes: []rune{'?'}, command: "help", desc: "显示本帮助",
+			action: func(t *TUI) { t.showHelp() }},
+		{label: ":", runes: []rune{':'}, desc: "输入命令",
+			action: func(t *TUI) { t.promptCommand() }},
+		{label: "q 或 Ctrl+C", runes: []rune{'q', 'Q'}, keys: []tcell.Key{tcell.KeyCtrlC}, command: "quit", desc: "退出程序",
+			action: func(t *TUI) { t.Stop() }},
+	}
+}
+
+// defaultCommands 返回默认的带参数命令表
+func defaultCommands() []command {
+	return []command{
+		{name: "add", usage: "<目标>", desc: "添加目标",
+			run: func(t *TUI, args []string) (string, error) {
+				if len(args) != 1 {
+					return "", errors.New("用法: add <目标>")
+				}
+				t.addTargetAsync(args[0])
+				return "", nil
+			}},
+		{name: "remove", aliases: []string{"rm"}, usage: "<目标>", desc: "移除目标",
+			run: func(t *TUI, args []string) (string, error) {
+				if len(args) != 1 {
+					return "", errors.New("用法: remove <目标>")
+				}
+				t.removeTargetAsync(args[0])
+				return "", nil
+			}},
+		{name: "interval", usage: "<间隔> [目标]", desc: "调整探测间隔，不指定目标时调整全局间隔",
+			run: func(t *TUI, args []string) (string, error) {
+				if len(args) < 1 || len(args) > 2 {
+					return "", errors.New("用法: interval <间隔> [目标]")
+				}
+				interval, err := time.ParseDuration(args[0])
+				if err != nil {
+					return "", fmt.Errorf("间隔 '%s' 无效: %v", args[0], err)
+				}
+				target := ""
+				if len(args) == 2 {
+					target = args[1]
+				}
+				if err := t.setInterval(target, interval); err != nil {
+					return "", err
+				}
+				if target == "" {
+					return fmt.Sprintf("探测间隔已调整为 %v", interval), nil
+				}
+				return fmt.Sprintf("%s 的探测间隔已调整为 %v", target, interval), nil
+			}},
+		{name: "sort", usage: "[列] [asc|desc]", desc: "按列排序表格（默认降序），不指定列时恢复原始顺序，可选列: " + strings.Join(ColumnNames(), ", "),
+			run: func(t *TUI, args []string) (string, error) {
+				if len(args) > 2 {
+					return "", errors.New("用法: sort [列] [asc|desc]")
+				}
+				if len(args) == 0 {
+					t.setSort("", false)
+					t.rebuildUI()
+					return "已恢复原始顺序", nil
+				}
+				descending := true
+				if len(args) == 2 {
+					switch args[1] {
+					case "asc":
+						descending = false
+					case "desc":
+					default:
+						return "", fmt.Errorf("排序方向 '%s' 无效，应为 asc 或 desc", args[1])
+					}
+				}
+				if err := t.setSort(args[0], descending); err != nil {
+					return "", err
+				}
+				t.rebuildUI()
+				col, _ := findColumn(args[0])
+				return fmt.Sprintf("按%s%s排列", col.header, sortDirectionName(descending)), nil
+			}},
+		{name: "renderer", usage: "<字符集>", desc: "切换图表字符集，可选: " + strings.Join(rendererNames, ", "),
+			run: func(t *TUI, args []string) (string, error) {
+				if len(args) != 1 {
+					return "", errors.New("用法: renderer <字符集>")
+				}
+				if err := t.setRenderer(args[0]); err != nil {
+					return "", err
+				}
+				t.updateChart()
+				return fmt.Sprintf("图表字符集已切换为 %s", t.glyphs.name), nil
+			}},
+		{name: "mark", usage: "[标签]", desc: "在当前时刻添加标记，图表中显示为竖线，并记入事件日志和导出文件",
+			run: func(t *TUI, args []string) (string, error) {
+				label := strings.Join(args, " ")
+				if label == "" {
+					t.statsMu.RLock()
+					label = fmt.Sprintf("标记%d", len(t.markers)+1)
+					t.statsMu.RUnlock()
+				}
+				t.addMarker(time.Now(), label)
+				t.updateChart()
+				return fmt.Sprintf("已添加标记 %s", label), nil
+			}},
+		{name: "export", usage: "<文件>", desc: "将统计数据、历史和事件导出为JSON文件",
+			run: func(t *TUI, args []string) (string, error) {
+				if len(args) != 1 {
+					return "", errors.New("用法: export <文件>")
+				}
+				if err := t.exportJSON(args[0]); err != nil {
+					return "", fmt.Errorf("导出失败: %v", err)
+				}
+				return fmt.Sprintf("已导出到 %s", args[0]), nil
+			}},
+	}
+}
+
+// panOrMoveCursor 光标模式下移动光标，否则平移视图
+func (t *TUI) panOrMoveCursor(direction int) {
+	if t.cursorMode {
+		t.moveCursor(direction)
+	} else {
+		t.panView(direction)
+	}
+	t.updateChart()
+}
+
+// dispatchKey 按绑定表处理按键，返回是否已处理
+func (t *TUI) dispatchKey(event *tcell.EventKey) bool {
+	for _, binding := range t.bindings {
+		if binding.matches(event) {
+			binding.action(t)
+			return true
+		}
+	}
+	return false
+}
+
+// executeCommand 执行一行命令，返回成功时显示的提示消息
+func (t *TUI) executeCommand(line string) (string, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	name, args := fields[0], fields[1:]
+
+	for _, cmd := range t.commands {
+		if cmd.name == name || containsString(cmd.aliases, name) {
+			return cmd.run(t, args)
+		}
+	}
+	for _, binding := range t.bindings {
+		if binding.command != "" && binding.command == name {
+			if len(args) > 0 {
+				return "", fmt.Errorf("命令 %s 不接受参数", name)
+			}
+			binding.action(t)
+			return "", nil
+		}
+	}
+	return "", fmt.Errorf("未知命令 '%s'，输入 :help 查看可用命令", name)
+}
+
+// promptCommand 弹出命令提示符
+func (t *TUI) promptCommand() {
+	t.showPrompt(":", "", func(text string) {
+		message, err := t.executeCommand(text)
+		if err != nil {
+			t.setMessage(fmt.Sprintf("[red]%s[white]", tview.Escape(err.Error())))
+			return
+		}
+		if message != "" {
+			t.setMessage(fmt.Sprintf("[green]%s[white]", tview.Escape(message)))
+		}
+	})
+}
+
+// helpText 根据按键绑定表和命令表生成帮助文本
+func (t *TUI) helpText() string {
+	labelWidth := 0
+	for _, binding := range t.bindings {
+		if width := tview.TaggedStringWidth(binding.label); width > labelWidth {
+			labelWidth = width
+		}
+	}
+
+	lines := []string{"[yellow]按键[white]"}
+	for _, binding := range t.bindings {
+		line := fmt.Sprintf("  %s  %s", padTagged(binding.label, labelWidth), binding.desc)
+		if binding.command != "" {
+			line += fmt.Sprintf(" [gray](:%s)[white]", binding.command)
+		}
+		lines = append(lines, line)
+	}
+
+	usages, usageWidth := make([]string, len(t.commands)), 0
+	for i, cmd := range t.commands {
+		usages[i] = tview.Escape(fmt.Sprintf(":%s %s", cmd.name, cmd.usage))
+		if width := tview.TaggedStringWidth(usages[i]); width > usageWidth {
+			usageWidth = width
+		}
+	}
+	lines = append(lines, "", "[yellow]命令[white]")
+	for i, cmd := range t.commands {
+		lines = append(lines, fmt.Sprintf("  %s  %s", padTagged(usages[i], usageWidth), cmd.desc))
+	}
+
+	lines = append(lines, "", "[gray]按任意键关闭[white]")
+	return strings.Join(lines, "\n")
+}
+
+// showHelp 显示帮助界面，只能在UI线程中调用
+func (t *TUI) showHelp() {
+	if t.testMode || t.pages == nil || t.helpShown {
+		return
+	}
+
+	text := t.helpText()
+	helpView := t.newTextView()
+	helpView.SetDynamicColors(true)
+	helpView.SetText(t.display(text))
+	helpView.SetBorder(true)
+	helpView.SetTitle(" 帮助 ")
+
+	// 帮助框居中显示，宽高按内容计算（加上边框）
+	width, height := 0, 0
+	for _, line := range strings.Split(text, "\n") {
+		if w := tview.TaggedStringWidth(line); w > width {
+			width = w
+		}
+		height++
+	}
+	overlay := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(helpView, height+2, 0, false).
+			AddItem(nil, 0, 1, false), width+4, 0, false).
+		AddItem(nil, 0, 1, false)
+
+	t.pages.AddPage(helpPage, overlay, true, true)
+	t.helpShown = true
+}
+
+// hideHelp 关闭帮助界面
+func (t *TUI) hideHelp() {
+	if !t.helpShown {
+		return
+	}
+	t.pages.RemovePage(helpPage)
+	t.helpShown = false
+}
+
+// containsString 判断字符串切片中是否包含指定字符串
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
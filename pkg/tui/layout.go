@@ -3,9 +3,8 @@ package tui
 
 import (
 	"fmt"
+	"time"
 
-	"github.com/Kevin-Rudy/goping/pkg/core"
-	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
@@ -14,15 +13,21 @@ func (t *TUI) setupUI() {
 	// 设置图表属性
 	t.chart.SetWordWrap(false)
 	t.chart.SetDynamicColors(true)
-	t.chart.SetText("[yellow]正在初始化，等待数据...[white]")
+	t.chart.SetText(t.display("[yellow]正在初始化，等待数据...[white]"))
+	t.histogramView.SetWordWrap(false)
+	t.histogramView.SetDynamicColors(true)
+	t.eventView.SetWordWrap(false)
+	t.eventView.SetDynamicColors(true)
+	t.statusBar.SetWordWrap(false)
+	t.statusBar.SetDynamicColors(true)
 
 	// 创建主垂直布局
 	t.flex = tview.NewFlex()
 	t.flex.SetDirection(tview.FlexRow)
 
 	// 添加初始的等待信息行
-	waitingInfo := tview.NewTextView()
-	waitingInfo.SetText("[green]GoPing 已启动[white] - [yellow]正在连接目标...[white]")
+	waitingInfo := t.newTextView()
+	waitingInfo.SetText(t.display("[green]GoPing 已启动[white] - [yellow]正在连接目标...[white]"))
 	waitingInfo.SetDynamicColors(true)
 	waitingInfo.SetTextAlign(tview.AlignCenter)
 
@@ -30,7 +35,9 @@ func (t *TUI) setupUI() {
 	t.flex.AddItem(waitingInfo, 1, 0, false)
 	t.flex.AddItem(t.chart, 0, 1, false)
 
-	t.app.SetRoot(t.flex, true)
+	t.pages = tview.NewPages()
+	t.pages.AddPage("main", t.flex, true, true)
+	t.app.SetRoot(t.pages, true)
 }
 
 // rebuildUI 重建UI布局
@@ -51,7 +58,7 @@ func (t *TUI) rebuildUI() {
 			identifiers = append(identifiers, target)
 		}
 	}
-	t.identifiers = identifiers
+	t.setIdentifiers(identifiers)
 
 	// 清空主布局
 	t.flex.Clear()
@@ -59,33 +66,22 @@ func (t *TUI) rebuildUI() {
 
 	if len(t.identifiers) == 0 {
 		// 没有数据时，显示等待界面
-		waitingInfo := tview.NewTextView()
-		waitingInfo.SetText("[green]GoPing 已启动[white] - [yellow]正在连接目标...[white]")
+		waitingInfo := t.newTextView()
+		waitingInfo.SetText(t.display("[green]GoPing 已启动[white] - [yellow]正在连接目标...[white]"))
 		waitingInfo.SetDynamicColors(true)
 		waitingInfo.SetTextAlign(tview.AlignCenter)
 
 		t.flex.AddItem(waitingInfo, 1, 0, false)
-		t.flex.AddItem(t.chart, 0, 1, false)
+		t.flex.AddItem(t.chartArea(), 0, 1, false)
+		t.addEventLogPane()
+		t.addBottomItems()
 		return
 	}
 
-	// 预计算：收集所有可能的 Summary keys
-	summaryKeysSet := make(map[string]bool)
-	for _, identifier := range t.identifiers {
-		if stats, exists := t.statsData[identifier]; exists {
-			for key := range stats.Summary {
-				summaryKeysSet[key] = true
-			}
-		}
-	}
-
-	// 按预定义顺序排列统计项
-	predefinedOrder := []string{"t/o", "丢包率", "发送/接收", "平均延迟", "最小延迟", "最大延迟"}
+	// 按配置的列顺序排列统计项
 	var summaryKeys []string
-	for _, key := range predefinedOrder {
-		if summaryKeysSet[key] {
-			summaryKeys = append(summaryKeys, key)
-		}
+	for _, col := range t.tableColumns() {
+		summaryKeys = append(summaryKeys, col.header)
 	}
 
 	// 构建完整的表头：目标 + 所有Summary keys
@@ -107,12 +103,10 @@ func (t *TUI) rebuildUI() {
 	}
 
 	// 最后添加图表，占据所有剩余空间
-	t.flex.AddItem(t.chart, 0, 1, false)
+	t.flex.AddItem(t.chartArea(), 0, 1, false)
+	t.addEventLogPane()
+	t.addBottomItems()
 
-	// 确保选择状态正确（注意现在索引需要+1，因为有表头行）
-	if t.selectedRow >= len(t.identifiers) {
-		t.selectedRow = len(t.identifiers) - 1
-	}
 	t.updateSelection()
 }
 
@@ -122,16 +116,35 @@ func (t *TUI) createHeaderRow(summaryKeys []string) *tview.Flex {
 	headerFlex.SetDirection(tview.FlexColumn)
 
 	// 添加表头的目标列
-	targetHeaderText := tview.NewTextView()
-	targetHeaderText.SetText(fmt.Sprintf("[yellow]%-20s[white]", "目标"))
+	targetHeaderText := t.newTextView()
+	targetHeaderText.SetText(t.display(fmt.Sprintf("[yellow]%-20s[white]", "目标")))
 	targetHeaderText.SetDynamicColors(true)
 	targetHeaderText.SetTextAlign(tview.AlignLeft)
 	headerFlex.AddItem(targetHeaderText, 0, 2, false) // 给目标列更多空间
 
+	// 迷你趋势图列
+	sparklineHeaderText := t.newTextView()
+	sparklineHeaderText.SetText(t.display(fmt.Sprintf("[yellow]%s[white]", sparklineHeader)))
+	sparklineHeaderText.SetDynamicColors(true)
+	sparklineHeaderText.SetTextAlign(tview.AlignLeft)
+	headerFlex.AddItem(sparklineHeaderText, sparklineWidth+1, 0, false)
+
 	// 添加表头的数据列
+	sortHeader := ""
+	if col, ok := findColumn(t.sortColumn); ok {
+		sortHeader = col.header
+	}
 	for _, header := range summaryKeys {
-		headerText := tview.NewTextView()
-		headerText.SetText(fmt.Sprintf("[yellow]%8s[white]", header))
+		// 排序列的表头标出排序方向
+		if header == sortHeader {
+			if t.sortDescending {
+				header += "▼"
+			} else {
+				header += "▲"
+			}
+		}
+		headerText := t.newTextView()
+		headerText.SetText(t.display(fmt.Sprintf("[yellow]%8s[white]", header)))
 		headerText.SetDynamicColors(true)
 		headerText.SetTextAlign(tview.AlignCenter)
 		headerFlex.AddItem(headerText, 0, 1, false)
@@ -141,7 +154,7 @@ func (t *TUI) createHeaderRow(summaryKeys []string) *tview.Flex {
 }
 
 // createDataRow 创建数据行
-func (t *TUI) createDataRow(identifier string, stats *core.Stats, summaryKeys []string) *tview.Flex {
+func (t *TUI) createDataRow(identifier string, stats *targetStats, summaryKeys []string) *tview.Flex {
 	rowFlex := tview.NewFlex()
 	rowFlex.SetDirection(tview.FlexColumn)
 
@@ -149,12 +162,28 @@ func (t *TUI) createDataRow(identifier string, stats *core.Stats, summaryKeys []
 	color := t.getTargetColor(identifier)
 
 	// 第一列：目标标识符（带颜色）
-	targetText := tview.NewTextView()
-	targetText.SetText(fmt.Sprintf("%s%-20s[white]", color, identifier))
+	targetText := t.newTextView()
+	name := identifier
+	if t.hiddenTargets[identifier] {
+		// 隐藏的目标以灰色显示并加注
+		color, name = "[gray]", identifier+" (隐藏)"
+	}
+	if style := t.lineStyle(identifier); style >= 0 {
+		// 以线型区分目标时，在目标名后显示线型示意
+		name += " " + lineStyleSamples[style]
+	}
+	targetText.SetText(t.display(fmt.Sprintf("%s%-20s[white]", color, name)))
 	targetText.SetDynamicColors(true)
 	targetText.SetTextAlign(tview.AlignLeft)
 	rowFlex.AddItem(targetText, 0, 2, false) // 给目标名称更多空间
 
+	// 第二列：最近延迟的迷你趋势图
+	sparklineText := t.newTextView()
+	sparklineText.SetText(t.display(sparkline(stats, sparklineWidth)))
+	sparklineText.SetDynamicColors(true)
+	sparklineText.SetTextAlign(tview.AlignLeft)
+	rowFlex.AddItem(sparklineText, sparklineWidth+1, 0, false)
+
 	// 其他列：严格按照预计算的 summaryKeys 顺序填充数据
 	for _, key := range summaryKeys {
 		value := "N/A" // 默认值
@@ -164,10 +193,10 @@ func (t *TUI) createDataRow(identifier string, stats *core.Stats, summaryKeys []
 			}
 		}
 
-		dataText := tview.NewTextView()
+		dataText := t.newTextView()
 		dataText.SetText(fmt.Sprintf("%8s", value))
 		dataText.SetTextAlign(tview.AlignCenter)
-		dataText.SetTextColor(tcell.ColorWhite)
+		dataText.SetTextStyle(t.cellStyle(stats, key))
 		rowFlex.AddItem(dataText, 0, 1, false)
 	}
 
@@ -183,6 +212,13 @@ func (t *TUI) updateChart() {
 	t.statsMu.RLock()
 	defer t.statsMu.RUnlock()
 
+	if t.eventLogShown {
+		t.eventView.SetText(t.display(t.drawEventLog(eventLogHeight)))
+	}
+	if t.tuiConfig.StatusBar {
+		t.statusBar.SetText(t.display(t.statusBarText(time.Now())))
+	}
+
 	if len(t.identifiers) == 0 {
 		t.chart.SetText("没有数据")
 		return
@@ -198,10 +234,23 @@ func (t *TUI) updateChart() {
 	if height < 10 {
 		height = 15
 	}
+	height-- // 第一行为标题行
 
 	var chartText string
-
-	if t.selectedRow == -1 {
+	t.plotAreas = t.plotAreas[:0] // 只有折线图会重新记录绘图区
+
+	if t.selectedRow == -1 && len(t.visibleTargets()) == 0 {
+		chartText = "所有目标均已隐藏，选择目标后按空格重新显示"
+	} else if t.chartMode == chartModeHeatmap {
+		// 热力图：全选时每个目标一个条带，单选时只显示选中目标
+		chartText = t.drawHeatmap(t.chartTargets(), width, height)
+	} else if t.chartMode == chartModeBand {
+		// 带状图：与热力图相同，每个目标一个条带
+		chartText = t.drawBandChart(t.chartTargets(), width, height)
+	} else if t.gridView && t.selectedRow == -1 {
+		// 网格视图：每个目标一个小折线图
+		chartText = t.drawGridChart(t.visibleTargets(), width, height)
+	} else if t.selectedRow == -1 {
 		// 全选状态：显示所有目标的折线图
 		chartText = t.drawMultiTargetChart(width, height)
 	} else if t.selectedRow >= 0 && t.selectedRow < len(t.identifiers) {
@@ -210,7 +259,22 @@ func (t *TUI) updateChart() {
 		chartText = t.drawSingleTargetChart(identifier, width, height)
 	}
 
-	t.chart.SetText(chartText)
+	// 图表视图第一行为标题行
+	for i := range t.plotAreas {
+		t.plotAreas[i].top++
+	}
+	t.chart.SetText(t.display(t.chartHeader() + "\n" + chartText))
+
+	if t.histogramShown {
+		_, _, histWidth, histHeight := t.histogramView.GetInnerRect()
+		if histWidth < 20 {
+			histWidth = histogramPanelWidth
+		}
+		if histHeight < 5 {
+			histHeight = height
+		}
+		t.histogramView.SetText(t.display(t.drawHistogram(t.selectedIdentifier(), histWidth, histHeight)))
+	}
 }
 
 // safeUIUpdate 安全地执行UI更新操作
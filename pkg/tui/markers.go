@@ -0,0 +1,107 @@
+// Package tui 时间轴标记模块
+// 用户可以在当前时刻添加带标签的标记（如变更开始、变更结束），
+// 标记在折线图中画成竖线，顶部显示标签，同时记入事件日志和导出文件
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+const (
+	markerLineChar = "┆"        // 标记竖线字符
+	markerColor    = "[yellow]" // 标记竖线和标签的颜色
+)
+
+// chartMarker 时间轴上的一个标记
+type chartMarker struct {
+	Time  time.Time // 标记时刻
+	Label string    // 标记标签
+}
+
+// promptAddMarker 弹出输入框，在当前时刻添加标记
+func (t *TUI) promptAddMarker() {
+	t.statsMu.RLock()
+	label := fmt.Sprintf("标记%d", len(t.markers)+1)
+	t.statsMu.RUnlock()
+
+	// 标记时刻为按下按键的时刻，而不是输入完标签的时刻
+	now := time.Now()
+	t.showPrompt("标记: ", label, func(text string) {
+		t.addMarker(now, text)
+		t.setMessage(fmt.Sprintf("[green]已添加标记 %s[white]", tview.Escape(text)))
+	})
+}
+
+// addMarker 在指定时刻添加标记，并记入事件日志
+func (t *TUI) addMarker(at time.Time, label string) {
+	t.statsMu.Lock()
+	t.markers = append(t.markers, chartMarker{Time: at, Label: label})
+	t.statsMu.Unlock()
+
+	t.recordEvents([]logEvent{{Time: at, Kind: eventMarker, Detail: label}})
+}
+
+// markerColumns 返回时间窗口内的标记所在的列，同一列有多个标记时保留最晚的一个
+// 调用者需持有statsMu
+func (t *TUI) markerColumns(windowStart, windowEnd time.Time, chartWidth int) map[int]chartMarker {
+	columns := make(map[int]chartMarker)
+	for _, marker := range t.markers {
+		if marker.Time.Before(windowStart) || !marker.Time.Before(windowEnd) {
+			continue
+		}
+		columns[t.timestampToX(marker.Time, windowStart, windowEnd, chartWidth)] = marker
+	}
+	return columns
+}
+
+// markerLabels 计算图表第一行中标记标签占用的单元格
+// 标签从标记竖线右侧开始，只占用没有折线的单元格，遇到折线、其他标记或图表边界时截断；
+// 返回值中的空字符串表示该单元格被左侧的双宽字符占用
+func (t *TUI) markerLabels(canvas [][]canvasCell, columns map[int]chartMarker, chartWidth int) map[int]string {
+	labels := make(map[int]string)
+	for col := 0; col < chartWidth; col++ {
+		marker, ok := columns[col]
+		if !ok {
+			continue
+		}
+		x := col + 1
+		for _, r := range marker.Label {
+			// 方括号会被tview当作颜色标签解析，替换为圆括号
+			switch r {
+			case '[':
+				r = '('
+			case ']':
+				r = ')'
+			}
+			width := tview.TaggedStringWidth(string(r))
+			if width == 0 || x+width > chartWidth || !t.labelCellsFree(canvas, columns, labels, x, width) {
+				break
+			}
+			labels[x] = string(r)
+			if width == 2 {
+				labels[x+1] = ""
+			}
+			x += width
+		}
+	}
+	return labels
+}
+
+// labelCellsFree 判断第一行中从x开始的width个单元格是否可以放置标签字符
+func (t *TUI) labelCellsFree(canvas [][]canvasCell, columns map[int]chartMarker, labels map[int]string, x, width int) bool {
+	for col := x; col < x+width; col++ {
+		if _, isMarker := columns[col]; isMarker {
+			return false
+		}
+		if _, used := labels[col]; used {
+			return false
+		}
+		if len(canvas[col]) > 0 && canvas[col][0].mask != 0 {
+			return false
+		}
+	}
+	return true
+}
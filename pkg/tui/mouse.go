@@ -0,0 +1,77 @@
+// Package tui 鼠标交互模块
+package tui
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// setupMouse 设置鼠标处理：点击表格行选择目标，在折线图上悬停或点击显示十字光标
+func (t *TUI) setupMouse() {
+	t.app.EnableMouse(true)
+	t.app.SetMouseCapture(func(event *tcell.EventMouse, action tview.MouseAction) (*tcell.EventMouse, tview.MouseAction) {
+		// 输入框和帮助界面显示时不处理鼠标
+		if t.prompt != nil || t.helpShown {
+			return event, action
+		}
+
+		x, y := event.Position()
+		if t.handleMouse(x, y, action) {
+			return nil, action
+		}
+		return event, action
+	})
+}
+
+// handleMouse 处理屏幕坐标(x, y)上的鼠标动作，返回是否已处理
+func (t *TUI) handleMouse(x, y int, action tview.MouseAction) bool {
+	switch action {
+	case tview.MouseLeftClick:
+		for i, rowFlex := range t.rowFlexes {
+			if rowFlex.InRect(x, y) {
+				// 第0行为表头，点击表头回到全选模式
+				t.selectRow(i - 1)
+				return true
+			}
+		}
+		return t.moveCursorTo(x, y)
+
+	case tview.MouseMove:
+		if t.moveCursorTo(x, y) {
+			return true
+		}
+		// 鼠标离开绘图区时隐藏光标
+		if t.cursorShown {
+			t.hideCursor()
+			t.updateChart()
+		}
+	}
+	return false
+}
+
+// moveCursorTo 鼠标位于折线图绘图区内时将光标移到鼠标所在的列，网格视图中按鼠标下方的小图换算
+func (t *TUI) moveCursorTo(x, y int) bool {
+	if t.chart == nil {
+		return false
+	}
+	chartX, chartY, _, _ := t.chart.GetInnerRect()
+	area, ok := t.plotAreaAt(x-chartX, y-chartY)
+	if !ok || !t.setCursorColumn(area, x-chartX-area.left) {
+		return false
+	}
+	t.updateChart()
+	return true
+}
+
+// selectRow 选择第index个目标，-1表示全选
+func (t *TUI) selectRow(index int) {
+	if index < -1 || index >= len(t.identifiers) {
+		return
+	}
+	t.selectedRow = index
+
+	if !t.testMode {
+		t.updateSelection()
+		t.updateChart()
+	}
+}
@@ -44,6 +44,28 @@ func WithHistorySize(size int) Option {
 	}
 }
 
+// WithHistoryRetention 设置历史数据保留时长
+func WithHistoryRetention(retention time.Duration) Option {
+	return func(c *Config) {
+		c.HistoryRetention = retention
+	}
+}
+
+// WithRollup 设置长期历史的聚合桶宽度和保留时长
+func WithRollup(interval, retention time.Duration) Option {
+	return func(c *Config) {
+		c.RollupInterval = interval
+		c.RollupRetention = retention
+	}
+}
+
+// WithZoomLevels 设置可切换的图表时间窗口
+func WithZoomLevels(levels ...time.Duration) Option {
+	return func(c *Config) {
+		c.ZoomLevels = levels
+	}
+}
+
 // WithValueBufferRatio 设置值缓冲比例
 func WithValueBufferRatio(ratio float64) Option {
 	return func(c *Config) {
@@ -51,6 +73,95 @@ func WithValueBufferRatio(ratio float64) Option {
 	}
 }
 
+// WithLogScale 设置Y轴是否默认使用对数刻度
+func WithLogScale(enabled bool) Option {
+	return func(c *Config) {
+		c.LogScale = enabled
+	}
+}
+
+// WithRelativeTime 设置X轴是否默认以相对当前时刻的时间标注
+func WithRelativeTime(enabled bool) Option {
+	return func(c *Config) {
+		c.RelativeTime = enabled
+	}
+}
+
+// WithLatencyThresholds 设置延迟的警告和严重阈值(ms)，0表示不启用
+// 阈值与最近探测的平均延迟比较，而不是自启动以来的平均延迟
+func WithLatencyThresholds(warning, critical float64) Option {
+	return func(c *Config) {
+		c.LatencyWarning = warning
+		c.LatencyCritical = critical
+	}
+}
+
+// WithLossThresholds 设置丢包率的警告和严重阈值(%)，0表示不启用
+// 阈值与最近探测的丢包率比较，而不是自启动以来的丢包率
+func WithLossThresholds(warning, critical float64) Option {
+	return func(c *Config) {
+		c.LossWarning = warning
+		c.LossCritical = critical
+	}
+}
+
+// WithEventLogSize 设置事件日志面板保留的事件条数
+func WithEventLogSize(size int) Option {
+	return func(c *Config) {
+		c.EventLogSize = size
+	}
+}
+
+// WithEventLogFile 设置事件日志文件路径，事件以追加方式写入
+func WithEventLogFile(path string) Option {
+	return func(c *Config) {
+		c.EventLogFile = path
+	}
+}
+
+// WithColumns 设置表格中显示的统计列及其顺序
+func WithColumns(columns ...string) Option {
+	return func(c *Config) {
+		c.Columns = columns
+	}
+}
+
+// WithSort 设置初始排序列和方向，column为空表示按目标的原始顺序
+func WithSort(column string, descending bool) Option {
+	return func(c *Config) {
+		c.SortColumn = column
+		c.SortDescending = descending
+	}
+}
+
+// WithTheme 设置内置颜色主题（dark、light、colorblind、mono）
+func WithTheme(name string) Option {
+	return func(c *Config) {
+		c.Theme = name
+	}
+}
+
+// WithCustomTheme 设置自定义颜色主题，覆盖WithTheme设置的内置主题
+func WithCustomTheme(theme *Theme) Option {
+	return func(c *Config) {
+		c.CustomTheme = theme
+	}
+}
+
+// WithStatusBar 设置是否显示底部状态栏
+func WithStatusBar(enabled bool) Option {
+	return func(c *Config) {
+		c.StatusBar = enabled
+	}
+}
+
+// WithRenderer 设置折线图字符集（auto、braille、block、ascii）
+func WithRenderer(name string) Option {
+	return func(c *Config) {
+		c.Renderer = name
+	}
+}
+
 // NewConfigWithOptions 使用选项模式创建TUI配置
 func NewConfigWithOptions(opts ...Option) *Config {
 	config := DefaultConfig()
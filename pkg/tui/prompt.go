@@ -0,0 +1,74 @@
+// Package tui 输入提示模块
+package tui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// messageDuration 底部提示消息的显示时长
+const messageDuration = 5 * time.Second
+
+// showPrompt 在界面底部显示输入框
+// 回车时以输入内容调用onSubmit，Esc取消；只能在UI线程中调用
+func (t *TUI) showPrompt(label, initial string, onSubmit func(text string)) {
+	if t.testMode || t.flex == nil {
+		return
+	}
+	if t.prompt != nil {
+		t.hidePrompt()
+	}
+
+	prompt := t.newInputField()
+	prompt.SetLabel(label)
+	prompt.SetText(initial)
+	prompt.SetFieldBackgroundColor(tcell.ColorDefault)
+	prompt.SetDoneFunc(func(key tcell.Key) {
+		text := strings.TrimSpace(prompt.GetText())
+		t.hidePrompt()
+		if key == tcell.KeyEnter && text != "" {
+			onSubmit(text)
+		}
+	})
+
+	t.prompt = prompt
+	t.flex.AddItem(prompt, 1, 0, true)
+	t.app.SetFocus(prompt)
+}
+
+// hidePrompt 隐藏输入框并恢复焦点
+func (t *TUI) hidePrompt() {
+	if t.prompt == nil {
+		return
+	}
+	t.flex.RemoveItem(t.prompt)
+	t.prompt = nil
+	t.app.SetFocus(t.flex)
+}
+
+// setMessage 设置底部提示消息，只能在UI线程中调用
+func (t *TUI) setMessage(message string) {
+	t.message = message
+	t.messageTime = time.Now()
+}
+
+// addBottomItems 在布局底部添加状态栏、输入框和提示消息
+func (t *TUI) addBottomItems() {
+	if t.tuiConfig.StatusBar && t.statusBar != nil {
+		t.flex.AddItem(t.statusBar, 1, 0, false)
+	}
+
+	if t.prompt != nil {
+		t.flex.AddItem(t.prompt, 1, 0, true)
+		return
+	}
+
+	if t.message != "" && time.Since(t.messageTime) < messageDuration {
+		messageView := t.newTextView()
+		messageView.SetDynamicColors(true)
+		messageView.SetText(t.display(t.message))
+		t.flex.AddItem(messageView, 1, 0, false)
+	}
+}
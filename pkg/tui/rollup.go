@@ -0,0 +1,260 @@
+// Package tui 长期历史聚合与降采样模块
+package tui
+
+import (
+	"math"
+	"time"
+
+	"github.com/Kevin-Rudy/goping/pkg/core"
+)
+
+// rollupBucket 表示一个时间桶内的聚合数据
+// 保留最小/最大值，使降采样后的图表仍能显示延迟尖峰；延迟分布只保存有样本的
+// 直方图桶区间，使降采样后仍能统计分位数和各延迟区间的样本数
+type rollupBucket struct {
+	start    time.Time // 桶的起始时间
+	min, max float64   // 桶内最小/最大延迟
+	sum      float64   // 桶内延迟之和，用于计算平均值
+	count    int       // 桶内有效延迟样本数
+	lost     int       // 桶内超时（丢失）的探测数
+	first    int       // counts[0]对应的直方图桶序号
+	counts   []int     // 从first开始的连续直方图桶的样本数
+}
+
+// avg 返回桶内的平均延迟，没有有效样本时返回NaN
+func (b rollupBucket) avg() float64 {
+	if b.count == 0 {
+		return math.NaN()
+	}
+	return b.sum / float64(b.count)
+}
+
+// add 将一个延迟样本计入桶中，NaN或无穷值计为丢失
+func (b *rollupBucket) add(value float64) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		b.lost++
+		return
+	}
+	if b.count == 0 || value < b.min {
+		b.min = value
+	}
+	if b.count == 0 || value > b.max {
+		b.max = value
+	}
+	b.sum += value
+	b.count++
+	b.addCount(latencyBucketIndex(value), 1)
+}
+
+// merge 将另一个桶的数据合并到当前桶中
+func (b *rollupBucket) merge(other rollupBucket) {
+	if other.count > 0 {
+		if b.count == 0 || other.min < b.min {
+			b.min = other.min
+		}
+		if b.count == 0 || other.max > b.max {
+			b.max = other.max
+		}
+		b.sum += other.sum
+		b.count += other.count
+		for i, count := range other.counts {
+			if count > 0 {
+				b.addCount(other.first+i, count)
+			}
+		}
+	}
+	b.lost += other.lost
+}
+
+// addCount 将n个样本计入指定的直方图桶，必要时向两端扩展counts
+func (b *rollupBucket) addCount(index, n int) {
+	switch {
+	case len(b.counts) == 0:
+		b.first = index
+		b.counts = []int{0}
+	case index < b.first:
+		counts := make([]int, b.first-index+len(b.counts))
+		copy(counts[b.first-index:], b.counts)
+		b.first, b.counts = index, counts
+	case index >= b.first+len(b.counts):
+		b.counts = append(b.counts, make([]int, index-b.first-len(b.counts)+1)...)
+	}
+	b.counts[index-b.first] += n
+}
+
+// histogram 返回桶内延迟分布的直方图
+func (b rollupBucket) histogram() *latencyHistogram {
+	histogram := newLatencyHistogram()
+	for i, count := range b.counts {
+		histogram.counts[b.first+i] += count
+		histogram.total += count
+	}
+	return histogram
+}
+
+// addToRollup 将一次探测结果计入对应的聚合桶，NaN表示丢失
+func (t *TUI) addToRollup(stats *targetStats, timestamp time.Time, value float64) {
+	bucket := t.findRollup(stats, timestamp, true)
+	bucket.add(value)
+	t.pruneRollups(stats)
+}
+
+// reviseRollup 将迟到回复对应的丢失记录改为有效延迟
+func (t *TUI) reviseRollup(stats *targetStats, timestamp time.Time, value float64) {
+	bucket := t.findRollup(stats, timestamp, false)
+	if bucket == nil || bucket.lost == 0 {
+		return
+	}
+	bucket.lost--
+	bucket.add(value)
+}
+
+// findRollup 返回时间戳所在的聚合桶，create为true时按时间顺序插入缺失的桶
+func (t *TUI) findRollup(stats *targetStats, timestamp time.Time, create bool) *rollupBucket {
+	start := timestamp.Truncate(t.tuiConfig.RollupInterval)
+
+	// 结果基本按时间顺序到达，从后向前查找
+	i := len(stats.rollups)
+	for i > 0 && stats.rollups[i-1].start.After(start) {
+		i--
+	}
+	if i > 0 && stats.rollups[i-1].start.Equal(start) {
+		return &stats.rollups[i-1]
+	}
+	if !create {
+		return nil
+	}
+
+	stats.rollups = append(stats.rollups, rollupBucket{})
+	copy(stats.rollups[i+1:], stats.rollups[i:])
+	stats.rollups[i] = rollupBucket{start: start}
+	return &stats.rollups[i]
+}
+
+// pruneRollups 移除超出聚合保留时长的桶
+func (t *TUI) pruneRollups(stats *targetStats) {
+	if len(stats.rollups) == 0 {
+		return
+	}
+
+	cutoff := stats.rollups[len(stats.rollups)-1].start.Add(-t.tuiConfig.RollupRetention)
+	drop := 0
+	for drop < len(stats.rollups) && stats.rollups[drop].start.Before(cutoff) {
+		drop++
+	}
+	if drop > 0 {
+		stats.rollups = stats.rollups[drop:]
+	}
+}
+
+// chartPoints 返回绘制指定窗口所需的数据点
+// 窗口内的原始数据点不多于columns时直接使用原始历史，否则按列降采样；
+// 原始历史覆盖不到窗口时改用聚合历史
+func (t *TUI) chartPoints(stats *targetStats, start, end time.Time, columns int) []core.DataPoint {
+	history := stats.History
+	if columns <= 0 || !end.After(start) {
+		return history
+	}
+
+	if t.useRollups(stats, start) {
+		return bucketsToPoints(downsampleRollups(stats.rollups, start, end, columns), end.Sub(start)/time.Duration(columns))
+	}
+
+	inWindow := 0
+	for _, point := range history {
+		if point.Timestamp.After(start) && point.Timestamp.Before(end) {
+			inWindow++
+		}
+	}
+	if inWindow <= columns {
+		return history
+	}
+	return bucketsToPoints(downsamplePoints(history, start, end, columns), end.Sub(start)/time.Duration(columns))
+}
+
+// useRollups 判断绘制从start开始的窗口是否需要使用聚合历史
+// 原始历史覆盖不到窗口起点，且聚合历史中有完整的桶早于原始历史时返回true
+func (t *TUI) useRollups(stats *targetStats, start time.Time) bool {
+	history, rollups := stats.History, stats.rollups
+	if len(rollups) == 0 {
+		return false
+	}
+	if len(history) == 0 {
+		return true
+	}
+	rawStart := history[0].Timestamp
+	return rawStart.After(start) && rollups[0].start.Add(t.tuiConfig.RollupInterval).Before(rawStart)
+}
+
+// newDisplayBuckets 将窗口均分为n个显示桶
+func newDisplayBuckets(start, end time.Time, n int) []rollupBucket {
+	buckets := make([]rollupBucket, n)
+	width := end.Sub(start) / time.Duration(n)
+	for i := range buckets {
+		buckets[i].start = start.Add(time.Duration(i) * width)
+	}
+	return buckets
+}
+
+// displayBucketIndex 返回时间戳所在的显示桶序号，不在窗口内时返回-1
+func displayBucketIndex(timestamp, start, end time.Time, n int) int {
+	if timestamp.Before(start) || !timestamp.Before(end) {
+		return -1
+	}
+	index := int(float64(timestamp.Sub(start)) / float64(end.Sub(start)) * float64(n))
+	if index >= n {
+		index = n - 1
+	}
+	return index
+}
+
+// downsamplePoints 将窗口内的原始数据点降采样为n个桶
+// 插值点不是真实的探测结果，不参与聚合
+func downsamplePoints(points []core.DataPoint, start, end time.Time, n int) []rollupBucket {
+	buckets := newDisplayBuckets(start, end, n)
+	for _, point := range points {
+		if point.Status == core.PointInterpolated || point.Status == core.PointPending {
+			continue
+		}
+		if index := displayBucketIndex(point.Timestamp, start, end, n); index >= 0 {
+			buckets[index].add(point.Value)
+		}
+	}
+	return buckets
+}
+
+// downsampleRollups 将窗口内的聚合桶合并为n个显示桶
+func downsampleRollups(rollups []rollupBucket, start, end time.Time, n int) []rollupBucket {
+	buckets := newDisplayBuckets(start, end, n)
+	for _, rollup := range rollups {
+		if index := displayBucketIndex(rollup.start, start, end, n); index >= 0 {
+			buckets[index].merge(rollup)
+		}
+	}
+	return buckets
+}
+
+// bucketsToPoints 将显示桶转换为绘图用的数据点
+// 每个桶在其中点输出平均值，折线连接各桶的平均值；最小值与最大值不同时
+// 额外输出两个范围点，绘制为该列的延迟范围，使尖峰在粗粒度下仍然可见；
+// 含有丢失的桶额外输出一个超时点，绘制到图表顶部
+func bucketsToPoints(buckets []rollupBucket, width time.Duration) []core.DataPoint {
+	points := make([]core.DataPoint, 0, len(buckets)*2)
+	for _, bucket := range buckets {
+		timestamp := bucket.start.Add(width / 2)
+		if bucket.lost > 0 {
+			points = append(points, core.DataPoint{Timestamp: timestamp, Value: math.NaN(), Status: core.PointTimeout})
+		}
+		if bucket.count == 0 {
+			continue
+		}
+		if bucket.min != bucket.max {
+			points = append(points,
+				core.DataPoint{Timestamp: timestamp, Value: bucket.min, Status: core.PointRange},
+				core.DataPoint{Timestamp: timestamp, Value: bucket.max, Status: core.PointRange},
+			)
+		}
+		points = append(points, core.DataPoint{Timestamp: timestamp, Value: bucket.avg(), Status: core.PointSuccess})
+	}
+	return points
+}
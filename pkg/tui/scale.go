@@ -0,0 +1,101 @@
+// Package tui Y轴刻度模块
+package tui
+
+import "math"
+
+const (
+	logScaleFloor   = 0.001 // 对数刻度下的最小值(ms)，避免对0取对数
+	yAxisLabelCount = 5     // 线性刻度下Y轴的刻度数
+	minLogTicks     = 3     // 对数刻度下至少需要的刻度数，不足时加密刻度序列
+)
+
+// logTickMantissas 对数刻度下依次尝试的刻度尾数：10的整数次幂、1-2-5序列、所有整数倍
+var logTickMantissas = [][]float64{{1}, {1, 2, 5}, {1, 2, 3, 4, 5, 6, 7, 8, 9}}
+
+// toggleLogScale 在线性刻度和对数刻度之间切换
+func (t *TUI) toggleLogScale() {
+	t.logScale = !t.logScale
+}
+
+// normalizeValue 将延迟值映射到[0, 1]区间，0对应minVal，1对应maxVal
+// 对数刻度下按对数均匀分布，使相差几个数量级的目标都能看清
+func (t *TUI) normalizeValue(value, minVal, maxVal float64) float64 {
+	if t.logScale {
+		value = math.Max(value, minVal)
+		return (math.Log(value) - math.Log(minVal)) / (math.Log(maxVal) - math.Log(minVal))
+	}
+	return (value - minVal) / (maxVal - minVal)
+}
+
+// denormalizeValue 是normalizeValue的逆运算，用于计算Y轴刻度对应的延迟值
+func (t *TUI) denormalizeValue(normalized, minVal, maxVal float64) float64 {
+	if t.logScale {
+		return math.Exp(math.Log(minVal) + normalized*(math.Log(maxVal)-math.Log(minVal)))
+	}
+	return minVal + normalized*(maxVal-minVal)
+}
+
+// logTicks 返回对数刻度下[minVal, maxVal]范围内的整齐刻度值，从小到大
+// 优先使用10的整数次幂，不足minLogTicks个时依次改用1-2-5序列和所有整数倍
+func logTicks(minVal, maxVal float64) []float64 {
+	var ticks []float64
+	for _, mantissas := range logTickMantissas {
+		ticks = ticks[:0]
+		for exp := math.Floor(math.Log10(minVal)); exp <= math.Ceil(math.Log10(maxVal)); exp++ {
+			for _, mantissa := range mantissas {
+				if value := mantissa * math.Pow(10, exp); value >= minVal && value <= maxVal {
+					ticks = append(ticks, value)
+				}
+			}
+		}
+		if len(ticks) >= minLogTicks {
+			break
+		}
+	}
+	return ticks
+}
+
+// yAxisLabels 返回折线图Y轴各行的刻度标签
+// 线性刻度下在数值上均匀分布；对数刻度下使用logTicks的整齐数值，每个刻度标在其数值所在的行，
+// 同一行只保留一个刻度，范围太窄、整齐刻度不足两个时退回均匀分布
+func (t *TUI) yAxisLabels(minVal, maxVal float64, rows int) map[int]string {
+	labels := make(map[int]string)
+
+	if t.logScale {
+		if ticks := logTicks(minVal, maxVal); len(ticks) >= 2 {
+			pixelHeight := rows * t.glyphs.cellHeight
+			for _, tick := range ticks {
+				y := int((1.0 - t.normalizeValue(tick, minVal, maxVal)) * float64(pixelHeight-1))
+				row := y / t.glyphs.cellHeight
+				if _, taken := labels[row]; !taken && row >= 0 && row < rows {
+					labels[row] = formatLatency(tick)
+				}
+			}
+			return labels
+		}
+	}
+
+	count := min(yAxisLabelCount, rows)
+	if count < 2 {
+		return labels
+	}
+	for i := 0; i < count; i++ {
+		// 在数值上均匀分布
+		normalized := float64(i) / float64(count-1)               // 0.0 到 1.0
+		value := t.denormalizeValue(1-normalized, minVal, maxVal) // 从最大值到最小值
+		// 计算对应的行号
+		labels[int(normalized*float64(rows-1))] = formatLatency(value)
+	}
+	return labels
+}
+
+// chartLabelWidth 返回折线图Y轴标签区域的宽度（含│分隔符和右侧空格）
+func (t *TUI) chartLabelWidth(minVal, maxVal float64) int {
+	maxLabelLen := max(len(formatLatency(maxVal)), len(formatLatency(minVal)))
+	if t.logScale {
+		for _, tick := range logTicks(minVal, maxVal) {
+			maxLabelLen = max(maxLabelLen, len(formatLatency(tick)))
+		}
+	}
+	return maxLabelLen + 2
+}
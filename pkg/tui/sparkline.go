@@ -0,0 +1,66 @@
+// Package tui 表格内联迷你趋势图模块
+package tui
+
+import (
+	"math"
+	"strings"
+
+	"github.com/Kevin-Rudy/goping/pkg/core"
+)
+
+// sparklineWidth 表格中迷你趋势图的宽度（字符数），每个字符对应一次探测
+const sparklineWidth = 20
+
+// sparklineHeader 迷你趋势图列的表头
+const sparklineHeader = "趋势"
+
+// sparklineBlocks 迷你趋势图使用的方块字符，从低到高
+var sparklineBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline 根据目标最近的探测结果生成迷你趋势图，调用方需持有statsMu
+// 每个字符对应一次探测，按该目标最近数据的最小/最大值缩放；丢失的探测以红色×标记
+func sparkline(stats *targetStats, width int) string {
+	// 从后向前收集最近的真实探测结果，跳过插值点和待定点
+	var recent []core.DataPoint
+	for i := len(stats.History) - 1; i >= 0 && len(recent) < width; i-- {
+		point := stats.History[i]
+		if point.Status == core.PointInterpolated || point.Status == core.PointPending {
+			continue
+		}
+		recent = append(recent, point)
+	}
+
+	minVal, maxVal := math.Inf(1), math.Inf(-1)
+	for _, point := range recent {
+		if !math.IsNaN(point.Value) {
+			minVal = math.Min(minVal, point.Value)
+			maxVal = math.Max(maxVal, point.Value)
+		}
+	}
+
+	var builder strings.Builder
+	currentColor := ""
+	setColor := func(color string) {
+		if color != currentColor {
+			builder.WriteString(color)
+			currentColor = color
+		}
+	}
+	for i := len(recent) - 1; i >= 0; i-- {
+		value := recent[i].Value
+		if math.IsNaN(value) {
+			setColor("[red]")
+			builder.WriteRune('×')
+			continue
+		}
+
+		level := 0
+		if maxVal > minVal {
+			level = int((value - minVal) / (maxVal - minVal) * float64(len(sparklineBlocks)-1))
+		}
+		setColor("[white]")
+		builder.WriteRune(sparklineBlocks[level])
+	}
+	setColor("[white]")
+	return builder.String()
+}
@@ -0,0 +1,90 @@
+// Package tui 底部状态栏模块
+// 状态栏显示探测引擎的实现方式和权限模式、探测间隔与超时、运行时长、每秒收到的结果数以及数据源丢弃的结果数
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Kevin-Rudy/goping/pkg/core"
+)
+
+// rateWindow 计算结果速率时统计的秒数
+const rateWindow = 5
+
+// rateCounter 按秒分桶统计事件数，用于计算最近几秒的平均速率
+type rateCounter struct {
+	seconds [rateWindow + 1]int64 // 每个桶对应的Unix秒
+	counts  [rateWindow + 1]int   // 每个桶中的事件数
+}
+
+// add 在now所在的秒记录一个事件
+func (r *rateCounter) add(now time.Time) {
+	second := now.Unix()
+	index := int(second % int64(len(r.seconds)))
+	if r.seconds[index] != second {
+		r.seconds[index] = second
+		r.counts[index] = 0
+	}
+	r.counts[index]++
+}
+
+// rate 返回now之前最近rateWindow个完整秒内的平均每秒事件数，当前这一秒尚未结束，不计入
+// since之后不足rateWindow秒时按实际经过的完整秒数计算
+func (r *rateCounter) rate(now, since time.Time) float64 {
+	current := now.Unix()
+	span := int64(rateWindow)
+	if elapsed := current - since.Unix(); elapsed < span {
+		span = elapsed
+	}
+	if span <= 0 {
+		return 0
+	}
+
+	total := 0
+	for i, second := range r.seconds {
+		if second < current && second >= current-span {
+			total += r.counts[i]
+		}
+	}
+	return float64(total) / float64(span)
+}
+
+// engineInfo 返回数据源报告的探测引擎实现方式和权限模式，数据源不支持时返回空字符串
+func (t *TUI) engineInfo() string {
+	reporter, ok := t.dataSource.(core.EngineReporter)
+	if !ok {
+		return ""
+	}
+	implementation, privilege := reporter.EngineInfo()
+	return implementation + " · " + privilege
+}
+
+// statusBarText 生成状态栏文本
+// 调用者需持有statsMu
+func (t *TUI) statusBarText(now time.Time) string {
+	var parts []string
+	if engine := t.engineInfo(); engine != "" {
+		parts = append(parts, engine)
+	}
+
+	probe := fmt.Sprintf("间隔 %v 超时 %v", t.pingerConfig.Interval, t.pingerConfig.Timeout)
+	if overrides := len(t.pingerConfig.Overrides); overrides > 0 {
+		probe += fmt.Sprintf("（%d个目标单独设置）", overrides)
+	}
+	parts = append(parts, probe)
+
+	parts = append(parts, "已运行 "+now.Sub(t.startTime).Truncate(time.Second).String())
+	parts = append(parts, fmt.Sprintf("%.1f 结果/秒", t.resultRate.rate(now, t.startTime)))
+
+	if counter, ok := t.dataSource.(core.DropCounter); ok {
+		if dropped := counter.DroppedResults(); dropped > 0 {
+			parts = append(parts, fmt.Sprintf("[red]丢弃 %d[gray]", dropped))
+		} else {
+			parts = append(parts, "丢弃 0")
+		}
+	}
+
+	return "[gray]" + strings.Join(parts, " │ ") + "[white]"
+}
@@ -0,0 +1,239 @@
+// Package tui 目标管理模块
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Kevin-Rudy/goping/pkg/core"
+	"github.com/rivo/tview"
+)
+
+// addTarget 在运行时添加监控目标
+// 需要数据源实现core.TargetManager接口
+func (t *TUI) addTarget(identifier string) error {
+	manager, ok := t.dataSource.(core.TargetManager)
+	if !ok {
+		return errors.New("当前数据源不支持运行时添加目标")
+	}
+
+	if err := manager.AddTarget(identifier); err != nil {
+		return err
+	}
+
+	t.statsMu.Lock()
+	defer t.statsMu.Unlock()
+
+	for _, target := range t.targets {
+		if target == identifier {
+			return nil
+		}
+	}
+	t.targets = append(t.targets, identifier)
+	t.assignColor(identifier)
+	return nil
+}
+
+// removeTarget 在运行时移除监控目标，并丢弃其统计数据
+// 其他目标的颜色和统计数据保持不变；数据源返回后记录移除时刻，
+// 此前发出的探测结果即使在同名目标重新添加后才到达也会被丢弃
+func (t *TUI) removeTarget(identifier string) error {
+	manager, ok := t.dataSource.(core.TargetManager)
+	if !ok {
+		return errors.New("当前数据源不支持运行时移除目标")
+	}
+
+	if err := manager.RemoveTarget(identifier); err != nil {
+		return err
+	}
+	t.forgetEvents(identifier)
+
+	t.statsMu.Lock()
+	defer t.statsMu.Unlock()
+
+	index := -1
+	for i, target := range t.targets {
+		if target == identifier {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return fmt.Errorf("目标 '%s' 不存在", identifier)
+	}
+
+	t.targets = append(t.targets[:index], t.targets[index+1:]...)
+	delete(t.statsData, identifier)
+	delete(t.hiddenTargets, identifier)
+	delete(t.resetTimes, identifier)
+	t.removedTargets[identifier] = time.Now()
+	return nil
+}
+
+// isStaleResult 判断目标在sendTime发出的探测结果是否属于已移除的目标
+// 目标移除后未重新添加时丢弃其所有结果；重新添加后只丢弃移除前发出的结果
+// 调用者需持有statsMu
+func (t *TUI) isStaleResult(identifier string, sendTime time.Time) bool {
+	removedAt, removed := t.removedTargets[identifier]
+	if !removed {
+		return false
+	}
+	if !sendTime.After(removedAt) {
+		return true
+	}
+	for _, target := range t.targets {
+		if target == identifier {
+			return false
+		}
+	}
+	return true
+}
+
+// countsTowardStats 判断目标在sendTime发出的探测是否计入表格统计
+// 重置统计之前发出的探测，包括重置后才收到的迟到和重复回复，只更新图表，不计入重置后的累加器
+// 调用者需持有statsMu
+func (t *TUI) countsTowardStats(identifier string, sendTime time.Time) bool {
+	resetAt, reset := t.resetTimes[identifier]
+	return !reset || !sendTime.Before(resetAt)
+}
+
+// selectedIdentifier 返回当前选中的目标，全选状态下返回空字符串
+func (t *TUI) selectedIdentifier() string {
+	if t.selectedRow >= 0 && t.selectedRow < len(t.identifiers) {
+		return t.identifiers[t.selectedRow]
+	}
+	return ""
+}
+
+// toggleTargetHidden 切换选中目标在全选图表中的隐藏状态
+// 隐藏的目标不参与全选图表的绘制和Y轴自动缩放，但继续探测并显示在表格中
+func (t *TUI) toggleTargetHidden() {
+	identifier := t.selectedIdentifier()
+	if identifier == "" {
+		t.setMessage("[yellow]请先用方向键选择要隐藏或显示的目标[white]")
+		return
+	}
+
+	t.statsMu.Lock()
+	hidden := !t.hiddenTargets[identifier]
+	if hidden {
+		t.hiddenTargets[identifier] = true
+	} else {
+		delete(t.hiddenTargets, identifier)
+	}
+	t.statsMu.Unlock()
+
+	if hidden {
+		t.setMessage(fmt.Sprintf("[green]已在全选图表中隐藏 %s[white]", tview.Escape(identifier)))
+	} else {
+		t.setMessage(fmt.Sprintf("[green]已在全选图表中显示 %s[white]", tview.Escape(identifier)))
+	}
+}
+
+// resetStats 清零选中目标的统计计数，全选时清零所有目标
+// 只重置表格使用的累加器，图表历史保留；重置记入事件日志，便于区分重置前后的统计
+func (t *TUI) resetStats() {
+	identifier := t.selectedIdentifier()
+	now := time.Now()
+
+	t.statsMu.Lock()
+	var events []logEvent
+	for _, target := range t.targets {
+		stats, exists := t.statsData[target]
+		if !exists || (identifier != "" && target != identifier) {
+			continue
+		}
+		stats.resetCounters()
+		t.resetTimes[target] = now
+		t.updateSummary(stats)
+		events = append(events, logEvent{Time: now, Identifier: target, Kind: eventReset, Detail: "统计已重置"})
+	}
+	t.statsMu.Unlock()
+
+	t.recordEvents(events)
+	if identifier != "" {
+		t.setMessage(fmt.Sprintf("[green]已重置 %s 的统计[white]", tview.Escape(identifier)))
+	} else {
+		t.setMessage("[green]已重置所有目标的统计[white]")
+	}
+}
+
+// visibleTargets 返回全选图表中需要绘制的目标，即未隐藏的目标
+// 调用者需持有statsMu
+func (t *TUI) visibleTargets() []string {
+	if len(t.hiddenTargets) == 0 {
+		return t.identifiers
+	}
+	visible := make([]string, 0, len(t.identifiers))
+	for _, identifier := range t.identifiers {
+		if !t.hiddenTargets[identifier] {
+			visible = append(visible, identifier)
+		}
+	}
+	return visible
+}
+
+// setInterval 在运行时调整探测间隔，target为空表示调整全局间隔
+// 需要数据源实现core.IntervalSetter接口
+func (t *TUI) setInterval(target string, interval time.Duration) error {
+	setter, ok := t.dataSource.(core.IntervalSetter)
+	if !ok {
+		return errors.New("当前数据源不支持调整探测间隔")
+	}
+
+	if err := setter.SetInterval(target, interval); err != nil {
+		return err
+	}
+
+	// 同步更新界面使用的探测设置，时间网格步长随之变化
+	t.statsMu.Lock()
+	defer t.statsMu.Unlock()
+
+	if target == "" {
+		t.pingerConfig.Interval = interval
+	} else {
+		t.pingerConfig.SetTargetOverride(target, interval, 0)
+	}
+	return nil
+}
+
+// promptAddTarget 弹出输入框添加目标
+func (t *TUI) promptAddTarget() {
+	t.showPrompt("添加目标: ", "", t.addTargetAsync)
+}
+
+// addTargetAsync 在后台添加目标，完成后显示结果消息
+// 目标解析可能较慢，放到后台执行，避免阻塞界面
+func (t *TUI) addTargetAsync(identifier string) {
+	go func() {
+		err := t.addTarget(identifier)
+		t.safeUIUpdate(func() {
+			if err != nil {
+				t.setMessage(fmt.Sprintf("[red]添加目标失败: %s[white]", tview.Escape(err.Error())))
+			} else {
+				t.setMessage(fmt.Sprintf("[green]已添加目标 %s[white]", tview.Escape(identifier)))
+			}
+		})
+	}()
+}
+
+// promptRemoveTarget 弹出输入框移除目标，默认填入当前选中的目标
+func (t *TUI) promptRemoveTarget() {
+	t.showPrompt("移除目标: ", t.selectedIdentifier(), t.removeTargetAsync)
+}
+
+// removeTargetAsync 在后台移除目标，完成后显示结果消息
+// 数据源需要等待目标正在进行的探测结束（最长约为一个超时时间），放到后台执行，避免阻塞界面
+func (t *TUI) removeTargetAsync(identifier string) {
+	go func() {
+		err := t.removeTarget(identifier)
+		t.safeUIUpdate(func() {
+			if err != nil {
+				t.setMessage(fmt.Sprintf("[red]移除目标失败: %s[white]", tview.Escape(err.Error())))
+			} else {
+				t.setMessage(fmt.Sprintf("[green]已移除目标 %s[white]", tview.Escape(identifier)))
+			}
+		})
+	}()
+}
@@ -0,0 +1,243 @@
+// Package tui 颜色主题模块
+// 界面代码中的颜色标签按语义使用固定的颜色名（white为普通文字、gray为次要信息、green/yellow/orange/red为状态等级、aqua为强调），
+// 主题在文本交给tview显示前将这些颜色名替换为主题中的颜色
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Theme 界面颜色主题
+// 颜色使用tview颜色标签的写法：颜色名或#rrggbb，"-"表示终端默认颜色，
+// 可以附带背景和属性，如"-::b"表示默认颜色加粗
+type Theme struct {
+	Name       string   `json:"name"`        // 主题名称
+	Palette    []string `json:"palette"`     // 目标颜色序列，按目标首次出现的顺序循环使用
+	Text       string   `json:"text"`        // 普通文字
+	Muted      string   `json:"muted"`       // 坐标轴、标签等次要信息
+	Good       string   `json:"good"`        // 正常状态
+	Warning    string   `json:"warning"`     // 警告状态和表头
+	Notice     string   `json:"notice"`      // 介于警告和严重之间的状态
+	Critical   string   `json:"critical"`    // 严重状态和超时
+	Accent     string   `json:"accent"`      // 光标等强调元素
+	Highlight  string   `json:"highlight"`   // 选中行的背景色
+	Background string   `json:"background"`  // 界面背景色，为空表示使用tview的默认背景
+	LineStyles bool     `json:"line_styles"` // 是否以不同的线型区分目标，适合颜色无法区分目标的主题
+}
+
+// themeNames 内置主题名称，顺序即帮助中的列出顺序
+var themeNames = []string{"dark", "light", "colorblind", "mono"}
+
+// builtinThemes 内置主题
+var builtinThemes = map[string]Theme{
+	// 深色终端，与引入主题之前的配色一致
+	"dark": {
+		Name: "dark",
+		Palette: []string{"green", "yellow", "blue", "red", "lightgreen", "lightblue", "lightyellow",
+			"lightred", "white", "gray", "darkgreen", "darkblue", "darkyellow", "darkred"},
+		Text: "white", Muted: "gray", Good: "green", Warning: "yellow", Notice: "orange",
+		Critical: "red", Accent: "aqua", Highlight: "darkcyan",
+	},
+	// 浅色终端：去掉在白色背景上看不清的浅色，使用终端默认背景
+	"light": {
+		Name: "light",
+		Palette: []string{"#005fd7", "#d75f00", "#008700", "#af00af", "#00879f", "#875f00",
+			"#5f00d7", "#d70087", "#5f8700", "#444444"},
+		Text: "black", Muted: "#6c6c6c", Good: "#008700", Warning: "#af5f00", Notice: "#d75f00",
+		Critical: "#d70000", Accent: "#0087af", Highlight: "#afd7ff", Background: "-",
+	},
+	// 红绿色盲友好：Okabe-Ito配色，状态等级使用蓝、黄、橙、朱红区分
+	"colorblind": {
+		Name:    "colorblind",
+		Palette: []string{"#e69f00", "#56b4e9", "#009e73", "#f0e442", "#0072b2", "#d55e00", "#cc79a7", "white"},
+		Text:    "white", Muted: "gray", Good: "#56b4e9", Warning: "#f0e442", Notice: "#e69f00",
+		Critical: "#d55e00", Accent: "#cc79a7", Highlight: "#0072b2",
+	},
+	// 单色：只使用终端默认颜色，状态以加粗、反色区分，目标以线型区分
+	"mono": {
+		Name:    "mono",
+		Palette: []string{"-"},
+		Text:    "-::-", Muted: "-::d", Good: "-", Warning: "-::b", Notice: "-::b",
+		Critical: "-::r", Accent: "-::r", Highlight: "gray", Background: "-", LineStyles: true,
+	},
+}
+
+// ThemeNames 返回所有内置主题的名称
+func ThemeNames() []string {
+	return append([]string(nil), themeNames...)
+}
+
+// BuiltinTheme 返回指定名称的内置主题
+func BuiltinTheme(name string) (*Theme, bool) {
+	theme, ok := builtinThemes[name]
+	if !ok {
+		return nil, false
+	}
+	theme.Palette = append([]string(nil), theme.Palette...)
+	return &theme, true
+}
+
+// LoadThemeFile 从JSON文件加载自定义主题
+// 文件中的"base"指定基础主题（默认为dark），其余字段覆盖基础主题中的对应颜色
+func LoadThemeFile(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var header struct {
+		Base string `json:"base"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("解析主题文件失败: %v", err)
+	}
+	if header.Base == "" {
+		header.Base = "dark"
+	}
+	theme, ok := BuiltinTheme(header.Base)
+	if !ok {
+		return nil, fmt.Errorf("未知的基础主题 '%s'", header.Base)
+	}
+	theme.Name = path
+
+	if err := json.Unmarshal(data, theme); err != nil {
+		return nil, fmt.Errorf("解析主题文件失败: %v", err)
+	}
+	if err := theme.Validate(); err != nil {
+		return nil, err
+	}
+	return theme, nil
+}
+
+// Validate 检查主题中的颜色是否有效
+func (th *Theme) Validate() error {
+	if len(th.Palette) == 0 {
+		return errors.New("主题的目标颜色序列不能为空")
+	}
+	specs := append([]string{th.Text, th.Muted, th.Good, th.Warning, th.Notice, th.Critical, th.Accent, th.Highlight, th.Background}, th.Palette...)
+	for _, spec := range specs {
+		if !validColorSpec(spec) {
+			return fmt.Errorf("主题中的颜色 '%s' 无效", spec)
+		}
+	}
+	return nil
+}
+
+// validColorSpec 判断颜色标签中的前景色是否为有效的颜色名、#rrggbb或"-"
+func validColorSpec(spec string) bool {
+	foreground, _, _ := strings.Cut(spec, ":")
+	return foreground == "" || foreground == "-" || tcell.GetColor(foreground) != tcell.ColorDefault
+}
+
+// themeColor 返回颜色标签中前景色对应的tcell颜色，"-"和空字符串为终端默认颜色
+func themeColor(spec string) tcell.Color {
+	foreground, _, _ := strings.Cut(spec, ":")
+	if foreground == "" || foreground == "-" {
+		return tcell.ColorDefault
+	}
+	return tcell.GetColor(foreground)
+}
+
+// themeStyle 返回颜色标签对应的文字样式，支持前景色和b(加粗)、d(暗淡)、u(下划线)、r(反色)属性
+func themeStyle(spec string) tcell.Style {
+	parts := strings.SplitN(spec, ":", 3)
+	style := tcell.StyleDefault.Foreground(themeColor(spec))
+	if len(parts) == 3 {
+		style = style.Bold(strings.Contains(parts[2], "b")).
+			Dim(strings.Contains(parts[2], "d")).
+			Underline(strings.Contains(parts[2], "u")).
+			Reverse(strings.Contains(parts[2], "r"))
+	}
+	return style
+}
+
+// themeTagPattern 匹配只包含颜色名的颜色标签，tview.Escape转义过的文本不会被匹配
+var themeTagPattern = regexp.MustCompile(`\[([a-z]+)\]`)
+
+// semanticColors 返回界面代码中使用的语义颜色名到主题颜色的映射，只包含需要替换的颜色名
+func (th *Theme) semanticColors() map[string]string {
+	colors := map[string]string{
+		"white":  th.Text,
+		"gray":   th.Muted,
+		"green":  th.Good,
+		"yellow": th.Warning,
+		"orange": th.Notice,
+		"red":    th.Critical,
+		"aqua":   th.Accent,
+	}
+	for name, spec := range colors {
+		if name == spec {
+			delete(colors, name)
+		}
+	}
+	return colors
+}
+
+// themed 将文本中的语义颜色标签替换为当前主题的颜色
+func (t *TUI) themed(text string) string {
+	if len(t.themeColors) == 0 {
+		return text
+	}
+	return themeTagPattern.ReplaceAllStringFunc(text, func(tag string) string {
+		if spec, ok := t.themeColors[tag[1:len(tag)-1]]; ok {
+			return "[" + spec + "]"
+		}
+		return tag
+	})
+}
+
+// styleBox 将主题的边框、标题和背景颜色应用到界面组件
+// 主题只作用于本界面创建的组件，不修改tview的全局样式
+func (t *TUI) styleBox(box *tview.Box) {
+	box.SetBorderColor(themeColor(t.theme.Muted))
+	box.SetTitleColor(themeColor(t.theme.Text))
+	if t.theme.Background != "" {
+		box.SetBackgroundColor(themeColor(t.theme.Background))
+	}
+}
+
+// newTextView 创建使用当前主题颜色的文本视图
+func (t *TUI) newTextView() *tview.TextView {
+	view := tview.NewTextView()
+	view.SetTextColor(themeColor(t.theme.Text))
+	t.styleBox(view.Box)
+	return view
+}
+
+// newInputField 创建使用当前主题颜色的输入框
+func (t *TUI) newInputField() *tview.InputField {
+	field := tview.NewInputField()
+	field.SetFieldTextColor(themeColor(t.theme.Text))
+	t.styleBox(field.Box)
+	return field
+}
+
+// lineStylePatterns 线型的像素模式，1表示绘制该像素；按目标的颜色序号循环使用
+var lineStylePatterns = []string{"1", "111000", "1000", "11110010"}
+
+// lineStyleSamples 各线型在表格中的示意
+var lineStyleSamples = []string{"──", "╌╌", "┈┈", "─·"}
+
+// lineStyle 返回目标的线型序号，主题未启用线型时返回-1
+func (t *TUI) lineStyle(identifier string) int {
+	if !t.theme.LineStyles {
+		return -1
+	}
+	return t.colorIndex[identifier] % len(lineStylePatterns)
+}
+
+// linePattern 返回目标的线型像素模式，实线返回空字符串
+func (t *TUI) linePattern(identifier string) string {
+	if style := t.lineStyle(identifier); style > 0 {
+		return lineStylePatterns[style]
+	}
+	return ""
+}
@@ -0,0 +1,128 @@
+// Package tui 告警阈值模块
+package tui
+
+import (
+	"math"
+
+	"github.com/Kevin-Rudy/goping/pkg/core"
+	"github.com/gdamore/tcell/v2"
+)
+
+// thresholdLevel 统计值相对告警阈值的级别
+type thresholdLevel int
+
+const (
+	levelNormal   thresholdLevel = iota // 未超过任何阈值
+	levelWarning                        // 超过警告阈值
+	levelCritical                       // 超过严重阈值
+)
+
+const (
+	thresholdLineChar = "╌" // 图表中阈值参考线使用的字符
+	thresholdSamples  = 30  // 告警级别按最近多少次探测计算
+)
+
+// classifyThreshold 判断值所处的告警级别，阈值为0表示未启用
+func classifyThreshold(value, warning, critical float64) thresholdLevel {
+	if math.IsNaN(value) {
+		return levelNormal
+	}
+	if critical > 0 && value >= critical {
+		return levelCritical
+	}
+	if warning > 0 && value >= warning {
+		return levelWarning
+	}
+	return levelNormal
+}
+
+// recentStats 返回目标最近thresholdSamples次探测的平均延迟和丢包率(%)，没有相应数据时返回NaN
+// 告警级别反映目标当前的状态，不使用自启动以来的累计值，否则长时间运行后新出现的问题难以触发告警；
+// 迟到的回复计为收到，插值点不是真实的探测结果，不参与统计
+func recentStats(stats *targetStats) (meanLatency, loss float64) {
+	var sum float64
+	probes, received := 0, 0
+	for i := len(stats.History) - 1; i >= 0 && probes < thresholdSamples; i-- {
+		point := stats.History[i]
+		switch point.Status {
+		case core.PointSuccess, core.PointLate:
+			probes++
+			if !math.IsNaN(point.Value) {
+				received++
+				sum += point.Value
+			}
+		case core.PointTimeout:
+			probes++
+		}
+	}
+
+	meanLatency, loss = math.NaN(), math.NaN()
+	if received > 0 {
+		meanLatency = sum / float64(received)
+	}
+	if probes > 0 {
+		loss = float64(probes-received) / float64(probes) * 100
+	}
+	return meanLatency, loss
+}
+
+// latencyLevel 返回目标最近平均延迟的告警级别
+func (t *TUI) latencyLevel(stats *targetStats) thresholdLevel {
+	meanLatency, _ := recentStats(stats)
+	return classifyThreshold(meanLatency, t.tuiConfig.LatencyWarning, t.tuiConfig.LatencyCritical)
+}
+
+// lossLevel 返回目标最近丢包率的告警级别
+func (t *TUI) lossLevel(stats *targetStats) thresholdLevel {
+	_, loss := recentStats(stats)
+	return classifyThreshold(loss, t.tuiConfig.LossWarning, t.tuiConfig.LossCritical)
+}
+
+// cellStyle 返回表格中统计项的文字样式，超过阈值时使用主题的警告或严重颜色
+func (t *TUI) cellStyle(stats *targetStats, key string) tcell.Style {
+	var level thresholdLevel
+	switch key {
+	case "平均延迟":
+		level = t.latencyLevel(stats)
+	case "丢包率":
+		level = t.lossLevel(stats)
+	}
+
+	switch level {
+	case levelCritical:
+		return themeStyle(t.theme.Critical)
+	case levelWarning:
+		return themeStyle(t.theme.Warning)
+	default:
+		return themeStyle(t.theme.Text)
+	}
+}
+
+// cellColor 返回表格中统计项的文字颜色
+func (t *TUI) cellColor(stats *targetStats, key string) tcell.Color {
+	foreground, _, _ := t.cellStyle(stats, key).Decompose()
+	return foreground
+}
+
+// thresholdRows 计算延迟阈值参考线所在的图表行，返回行号到颜色标签的映射
+// 不在当前值范围内的阈值不绘制；两条线落在同一行时显示严重阈值的颜色
+func (t *TUI) thresholdRows(minVal, maxVal float64, chartBodyHeight int) map[int]string {
+	rows := make(map[int]string)
+	thresholds := []struct {
+		value float64
+		color string
+	}{
+		{t.tuiConfig.LatencyWarning, "[yellow]"},
+		{t.tuiConfig.LatencyCritical, "[red]"},
+	}
+	for _, threshold := range thresholds {
+		if threshold.value <= 0 || threshold.value < minVal || threshold.value > maxVal {
+			continue
+		}
+		// 与数据点使用相同的子像素映射，保证参考线与数据线对齐
+		cellHeight := t.glyphs.cellHeight
+		pixelY := int((1.0 - t.normalizeValue(threshold.value, minVal, maxVal)) * float64(chartBodyHeight*cellHeight-1))
+		rows[pixelY/cellHeight] = threshold.color
+	}
+	return rows
+}
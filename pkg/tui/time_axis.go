@@ -0,0 +1,220 @@
+// Package tui 时间轴模块
+// X轴按时钟时间或相对当前时刻的时间标注刻度，刻度间隔根据时间窗口长度和图表宽度自动选择，
+// 保证标签互不重叠；图表上方的标题行显示会话开始时间
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// timeTickSteps 候选的刻度间隔，从小到大，均能整除一天，时钟时间下刻度落在整点、整分
+var timeTickSteps = []time.Duration{
+	time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second, 15 * time.Second, 30 * time.Second,
+	time.Minute, 2 * time.Minute, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 2 * time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour, 24 * time.Hour,
+}
+
+// timeTickGap 相邻刻度标签之间至少保留的空格数
+const timeTickGap = 2
+
+// timeTick X轴上的一个刻度
+type timeTick struct {
+	col   int    // 刻度所在的列，相对于绘图区左边界
+	label string // 刻度标签
+}
+
+// toggleRelativeTime 在时钟时间和相对时间之间切换X轴标签
+func (t *TUI) toggleRelativeTime() {
+	t.relativeTime = !t.relativeTime
+}
+
+// timeTicks 计算时间窗口内的X轴刻度
+// 时钟时间的刻度对齐到本地时间的整数倍间隔；相对时间的刻度以now为零点向前每隔一个间隔一个，
+// 窗口中晚于now的部分（启动后的填充阶段）不标注
+func (t *TUI) timeTicks(chartWidth int, windowStart, windowEnd, now time.Time) []timeTick {
+	window := windowEnd.Sub(windowStart)
+	if window <= 0 || chartWidth <= 0 {
+		return nil
+	}
+
+	step := t.timeTickStep(chartWidth, window)
+	var times []time.Time
+	if t.relativeTime {
+		// 从窗口内最晚的刻度开始向前
+		reference := now
+		if windowEnd.Before(now) {
+			reference = now.Add(-now.Sub(windowEnd).Truncate(step))
+			if reference.After(windowEnd) {
+				reference = reference.Add(-step)
+			}
+		}
+		for tick := reference; !tick.Before(windowStart); tick = tick.Add(-step) {
+			times = append(times, tick)
+		}
+		sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	} else {
+		midnight := time.Date(windowStart.Year(), windowStart.Month(), windowStart.Day(), 0, 0, 0, 0, windowStart.Location())
+		first := midnight.Add(windowStart.Sub(midnight).Truncate(step))
+		if first.Before(windowStart) {
+			first = first.Add(step)
+		}
+		for tick := first; !tick.After(windowEnd); tick = tick.Add(step) {
+			times = append(times, tick)
+		}
+	}
+
+	ticks := make([]timeTick, 0, len(times))
+	for _, tick := range times {
+		col := t.timestampToX(tick, windowStart, windowEnd, chartWidth)
+		if col >= chartWidth {
+			col = chartWidth - 1
+		}
+		ticks = append(ticks, timeTick{col: col, label: t.timeTickLabel(tick, now, step)})
+	}
+	return ticks
+}
+
+// timeTickStep 选择使标签互不重叠的最小刻度间隔，窗口过长时使用最大的间隔
+func (t *TUI) timeTickStep(chartWidth int, window time.Duration) time.Duration {
+	for _, step := range timeTickSteps {
+		labelWidth := t.timeTickLabelWidth(step, window)
+		spacing := float64(chartWidth) * float64(step) / float64(window)
+		if spacing >= float64(labelWidth+timeTickGap) {
+			return step
+		}
+	}
+	return timeTickSteps[len(timeTickSteps)-1]
+}
+
+// timeTickLabelWidth 估算某个刻度间隔下最宽的标签宽度
+// 相对时间取窗口内最早的两个刻度中较宽的一个，如窗口5m、间隔30s时为"-4m30s"
+func (t *TUI) timeTickLabelWidth(step, window time.Duration) int {
+	if t.relativeTime {
+		earliest := window.Truncate(step)
+		return max(len(formatRelativeTime(-earliest)), len(formatRelativeTime(step-earliest)))
+	}
+	if step < time.Minute {
+		return len("15:04:05")
+	}
+	return len("15:04")
+}
+
+// timeTickLabel 生成刻度标签：相对时间如"-30s"，时钟时间如"15:04:05"，间隔不小于一分钟时省略秒，
+// 间隔不小于一小时时零点的刻度显示日期
+func (t *TUI) timeTickLabel(tick, now time.Time, step time.Duration) string {
+	if t.relativeTime {
+		return formatRelativeTime(tick.Sub(now))
+	}
+	switch {
+	case step < time.Minute:
+		return tick.Format("15:04:05")
+	case step >= time.Hour && tick.Hour() == 0 && tick.Minute() == 0:
+		return tick.Format("01-02")
+	default:
+		return tick.Format("15:04")
+	}
+}
+
+// formatRelativeTime 将相对当前时刻的偏移格式化为简短形式，如 -30s、-5m、-1h30m，零点显示为"现在"
+func formatRelativeTime(offset time.Duration) string {
+	offset = offset.Round(time.Second)
+	if offset == 0 {
+		return "现在"
+	}
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	hours, minutes, seconds := offset/time.Hour, offset%time.Hour/time.Minute, offset%time.Minute/time.Second
+	switch {
+	case hours > 0 && minutes > 0:
+		return fmt.Sprintf("%s%dh%dm", sign, hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%s%dh", sign, hours)
+	case minutes > 0 && seconds > 0:
+		return fmt.Sprintf("%s%dm%ds", sign, minutes, seconds)
+	case minutes > 0:
+		return fmt.Sprintf("%s%dm", sign, minutes)
+	default:
+		return fmt.Sprintf("%s%ds", sign, seconds)
+	}
+}
+
+// drawTimeAxis 绘制X轴及其下方的刻度标签行
+// 标签以刻度为中心，靠近边界时向内移动，与前一个标签重叠的标签不显示；
+// 暂停、回看或切换视图时，在标签行中间显示视图状态，与之重叠的标签让位于视图状态
+func (t *TUI) drawTimeAxis(yAxisLabelWidth, chartWidth int, windowStart, windowEnd time.Time) []string {
+	ticks := t.timeTicks(chartWidth, windowStart, windowEnd, time.Now())
+
+	viewStatus := t.viewStatus()
+	statusWidth := tview.TaggedStringWidth(viewStatus)
+	statusStart := (chartWidth - statusWidth) / 2
+	if statusStart < 0 {
+		statusStart = 0
+	}
+
+	axis := []rune(strings.Repeat("─", chartWidth))
+	var labels strings.Builder
+	pos := 0
+	statusDrawn := viewStatus == ""
+	for _, tick := range ticks {
+		if tick.col >= 0 && tick.col < chartWidth {
+			axis[tick.col] = '┬'
+		}
+
+		width := tview.TaggedStringWidth(tick.label)
+		start := tick.col - width/2
+		if start > chartWidth-width {
+			start = chartWidth - width
+		}
+		if start < 0 {
+			start = 0
+		}
+		if !statusDrawn && start+width+1 > statusStart {
+			if start < statusStart+statusWidth+1 {
+				continue // 与视图状态重叠
+			}
+			pos = writeAxisLabel(&labels, pos, statusStart, "[yellow]"+viewStatus+"[gray]", statusWidth)
+			statusDrawn = true
+		}
+		if start < pos || start+width > chartWidth {
+			continue
+		}
+		pos = writeAxisLabel(&labels, pos, start, tick.label, width)
+	}
+	if !statusDrawn {
+		writeAxisLabel(&labels, pos, statusStart, "[yellow]"+viewStatus+"[gray]", statusWidth)
+	}
+
+	return []string{
+		fmt.Sprintf("[gray]%-*s└%s[white]", yAxisLabelWidth-1, "", string(axis)),
+		fmt.Sprintf("[gray]%*s%s[white]", yAxisLabelWidth, "", labels.String()),
+	}
+}
+
+// writeAxisLabel 在标签行的start列写入标签，返回下一个标签可以开始的列
+func writeAxisLabel(labels *strings.Builder, pos, start int, label string, width int) int {
+	if start < pos {
+		start = pos
+	}
+	labels.WriteString(strings.Repeat(" ", start-pos))
+	labels.WriteString(label)
+	labels.WriteString(" ")
+	return start + width + 1
+}
+
+// chartHeader 生成图表上方的标题行，显示会话开始时间和时间轴的标注方式
+func (t *TUI) chartHeader() string {
+	header := "会话开始 " + t.startTime.Format("2006-01-02 15:04:05")
+	if t.relativeTime {
+		header += "  X轴: 相对当前时刻"
+	} else {
+		header += "  X轴: 时钟时间"
+	}
+	return "[gray]" + header + "[white]"
+}
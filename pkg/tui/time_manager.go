@@ -2,14 +2,30 @@
 package tui
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 )
 
+// panStepRatio 每次左右平移的距离占时间窗口的比例
+const panStepRatio = 0.2
+
 // getTimeWindow 获取当前的时间窗口
+// 暂停时返回冻结的视图窗口，否则跟随当前时间
 func (t *TUI) getTimeWindow() (start, end time.Time) {
+	windowDuration := t.windowDuration()
+	if t.paused {
+		return t.viewEnd.Add(-windowDuration), t.viewEnd
+	}
+	return t.liveTimeWindow()
+}
+
+// liveTimeWindow 获取跟随当前时间的实时窗口
+func (t *TUI) liveTimeWindow() (start, end time.Time) {
 	now := time.Now()
 	elapsed := now.Sub(t.startTime)
-	windowDuration := time.Duration(t.tuiConfig.MaxHistorySize) * t.tuiConfig.TimeGridInterval
+	windowDuration := t.windowDuration()
 
 	if elapsed < windowDuration {
 		// 填充阶段：固定窗口，从启动时间开始
@@ -20,6 +36,157 @@ func (t *TUI) getTimeWindow() (start, end time.Time) {
 	}
 }
 
+// windowDuration 返回当前缩放级别下图表时间窗口的长度
+func (t *TUI) windowDuration() time.Duration {
+	windows := t.zoomWindows()
+	if t.zoomIndex < 0 || t.zoomIndex >= len(windows) {
+		return windows[0]
+	}
+	return windows[t.zoomIndex]
+}
+
+// baseWindow 返回默认的图表时间窗口长度
+func (t *TUI) baseWindow() time.Duration {
+	return time.Duration(t.tuiConfig.MaxHistorySize) * t.tuiConfig.TimeGridInterval
+}
+
+// zoomWindows 返回所有可切换的时间窗口，从小到大排列，第一个为默认窗口
+func (t *TUI) zoomWindows() []time.Duration {
+	base := t.baseWindow()
+	windows := []time.Duration{base}
+	for _, level := range t.tuiConfig.ZoomLevels {
+		if level > base {
+			windows = append(windows, level)
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i] < windows[j] })
+
+	// 去除重复的缩放级别
+	unique := windows[:1]
+	for _, window := range windows[1:] {
+		if window != unique[len(unique)-1] {
+			unique = append(unique, window)
+		}
+	}
+	return unique
+}
+
+// zoomIn 切换到更小的时间窗口
+func (t *TUI) zoomIn() {
+	if t.zoomIndex > 0 {
+		t.zoomIndex--
+		t.clampView()
+	}
+}
+
+// zoomOut 切换到更大的时间窗口
+func (t *TUI) zoomOut() {
+	if t.zoomIndex < len(t.zoomWindows())-1 {
+		t.zoomIndex++
+		t.clampView()
+	}
+}
+
+// retention 返回原始历史数据的保留时长，至少为一个默认时间窗口
+func (t *TUI) retention() time.Duration {
+	if t.tuiConfig.HistoryRetention < t.baseWindow() {
+		return t.baseWindow()
+	}
+	return t.tuiConfig.HistoryRetention
+}
+
+// viewableHistory 返回可回看的历史时长，取原始历史与聚合历史中较长者
+func (t *TUI) viewableHistory() time.Duration {
+	if t.tuiConfig.RollupRetention > t.retention() {
+		return t.tuiConfig.RollupRetention
+	}
+	return t.retention()
+}
+
+// togglePause 暂停或恢复图表视图，暂停期间后台探测和统计照常进行
+func (t *TUI) togglePause() {
+	if t.paused {
+		t.resumeLive()
+		return
+	}
+	_, t.viewEnd = t.liveTimeWindow()
+	t.paused = true
+}
+
+// resumeLive 回到实时视图
+func (t *TUI) resumeLive() {
+	t.paused = false
+	t.viewEnd = time.Time{}
+}
+
+// panView 在保留的历史中平移视图，direction为负表示向过去平移
+// 实时视图下平移会自动进入暂停状态
+func (t *TUI) panView(direction int) {
+	if !t.paused {
+		t.togglePause()
+	}
+
+	step := time.Duration(float64(t.windowDuration()) * panStepRatio)
+	t.viewEnd = t.viewEnd.Add(time.Duration(direction) * step)
+	t.clampView()
+}
+
+// clampView 将暂停的视图限制在可回看的历史范围内：不早于最早可用数据，不晚于实时窗口
+func (t *TUI) clampView() {
+	if !t.paused {
+		return
+	}
+
+	_, liveEnd := t.liveTimeWindow()
+	earliest := liveEnd.Add(-t.viewableHistory())
+	if earliest.Before(t.startTime) {
+		earliest = t.startTime
+	}
+	minEnd := earliest.Add(t.windowDuration())
+	if t.viewEnd.Before(minEnd) {
+		t.viewEnd = minEnd
+	}
+	if t.viewEnd.After(liveEnd) {
+		t.viewEnd = liveEnd
+	}
+}
+
+// viewStatus 返回视图状态描述，默认视图（实时、默认窗口、线性刻度的折线图）返回空字符串
+func (t *TUI) viewStatus() string {
+	var parts []string
+	if t.chartMode != chartModeLine {
+		parts = append(parts, t.chartMode.String())
+	}
+	if t.logScale {
+		parts = append(parts, "对数刻度")
+	}
+	if t.zoomIndex > 0 {
+		parts = append(parts, "窗口 "+formatWindow(t.windowDuration()))
+	}
+	if t.paused {
+		_, liveEnd := t.liveTimeWindow()
+		offset := liveEnd.Sub(t.viewEnd).Round(time.Second)
+		if offset <= 0 {
+			parts = append(parts, "已暂停")
+		} else {
+			parts = append(parts, fmt.Sprintf("已暂停 -%v", offset))
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+// formatWindow 将时间窗口格式化为简短形式，如 5m、1h
+func formatWindow(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return d.String()
+	}
+}
+
 // timestampToX 将时间戳转换为X坐标
 func (t *TUI) timestampToX(timestamp time.Time, windowStart, windowEnd time.Time, chartWidth int) int {
 	windowDuration := windowEnd.Sub(windowStart)
@@ -39,3 +206,25 @@ func (t *TUI) timestampToX(timestamp time.Time, windowStart, windowEnd time.Time
 	x := int(float64(offset) / float64(windowDuration) * float64(chartWidth))
 	return x
 }
+
+// targetInterval 返回目标的探测间隔，用作该目标时间网格的步长
+func (t *TUI) targetInterval(identifier string) time.Duration {
+	interval, _ := t.pingerConfig.TargetSettings(identifier)
+	return interval
+}
+
+// targetTimeoutThreshold 返回目标的超时判定阈值
+func (t *TUI) targetTimeoutThreshold(identifier string) time.Duration {
+	_, timeout := t.pingerConfig.TargetSettings(identifier)
+	return t.tuiConfig.GetTimeoutThreshold(timeout)
+}
+
+// historyCapacity 返回目标历史缓冲区的容量
+// 容量按保留时长和目标的探测间隔计算，探测越频繁需要的数据点越多
+func (t *TUI) historyCapacity(identifier string) int {
+	capacity := int(t.retention() / t.targetInterval(identifier))
+	if capacity < t.tuiConfig.MaxHistorySize {
+		capacity = t.tuiConfig.MaxHistorySize
+	}
+	return capacity
+}
@@ -3,6 +3,9 @@
 package tui
 
 import (
+	"context"
+	"fmt"
+	"os"
 	"sync"
 	"time"
 
@@ -17,24 +20,52 @@ type TUI struct {
 	rowFlexes  []*tview.Flex
 	chart      *tview.TextView
 	flex       *tview.Flex
+	pages      *tview.Pages // 根布局，帮助界面作为覆盖层叠加在主布局之上
 	dataSource core.DataSource
 
 	// 配置信息
-	tuiConfig        *Config       // TUI配置
-	timeoutThreshold time.Duration // 计算得出的超时阈值
+	tuiConfig        *Config        // TUI配置
+	pingerConfig     *pinger.Config // Pinger配置的副本，用于获取每个目标的探测间隔和超时，运行时调整间隔只修改副本
+	timeoutThreshold time.Duration  // 计算得出的超时阈值
 
 	// 数据存储
-	statsData map[string]*core.Stats
+	statsData map[string]*targetStats
 	statsMu   sync.RWMutex
 
 	// 界面状态
 	selectedRow int
 	headers     []string
 	identifiers []string
-	targets     []string // 保存命令行输入的目标顺序
+	targets     []string // 保存目标顺序（命令行输入的目标及运行时添加的目标）
+
+	// 目标管理
+	colorIndex     map[string]int       // 目标到颜色序号的映射，保证增删目标时已有目标颜色不变
+	nextColorIndex int                  // 下一个待分配的颜色序号
+	removedTargets map[string]time.Time // 已移除的目标及移除时刻，忽略该时刻之前发出的探测结果
+	resetTimes     map[string]time.Time // 目标最近一次重置统计的时刻，该时刻之前发出的探测不计入表格统计
+	hiddenTargets  map[string]bool      // 在全选图表中隐藏的目标，仍然继续探测并显示在表格中
+
+	// 输入提示与消息
+	prompt      *tview.InputField // 当前显示的输入框，nil表示未显示
+	message     string            // 底部提示消息
+	messageTime time.Time         // 提示消息的显示时间
+
+	// 颜色主题
+	theme       *Theme            // 当前颜色主题
+	themeColors map[string]string // 语义颜色名到主题颜色的替换表
+
+	// 表格排序
+	sortColumn     string // 排序列名，为空表示按目标的原始顺序
+	sortDescending bool   // 是否降序排列
+
+	// 按键绑定与命令
+	bindings  []keyBinding // 按键绑定表
+	commands  []command    // 带参数的命令表
+	helpShown bool         // 是否显示帮助界面
 
 	// 控制
 	stopChan chan struct{}
+	stopOnce sync.Once
 	doneChan chan struct{}
 
 	// 测试模式标志
@@ -42,52 +73,160 @@ type TUI struct {
 
 	// 时间管理
 	startTime time.Time // 程序启动时间，用于时间窗口计算
+	paused    bool      // 图表视图是否暂停
+	viewEnd   time.Time // 暂停或回看时视图窗口的结束时间
+	zoomIndex int       // 当前缩放级别在zoomWindows中的序号，0为默认窗口
+
+	// 图表视图
+	chartMode    chartMode    // 当前图表绘制模式
+	logScale     bool         // Y轴是否使用对数刻度
+	relativeTime bool         // X轴是否以相对当前时刻的时间标注
+	glyphs       *chartGlyphs // 折线图使用的字符集
+
+	// 十字光标
+	cursorMode   bool          // 是否处于光标模式，光标模式下左右方向键移动光标
+	cursorShown  bool          // 是否显示光标
+	cursorOffset time.Duration // 光标距窗口结束时间的偏移
+	plotAreas    []plotArea    // 最近一次绘制的折线图绘图区，网格视图中每个小图一个
+
+	// 时间轴标记
+	markers []chartMarker // 用户添加的标记，由statsMu保护
+
+	// 网格视图
+	gridView        bool // 全选时是否以网格形式为每个目标绘制小图
+	gridSharedScale bool // 网格中的小图是否共享Y轴刻度
+
+	// 延迟分布直方图
+	histogramView  *tview.TextView // 直方图面板
+	histogramShown bool            // 是否显示直方图面板
+	histogramScope histogramScope  // 直方图的统计范围
+
+	// 事件日志
+	eventView     *tview.TextView              // 事件日志面板
+	eventLogShown bool                         // 是否显示事件日志面板
+	eventScroll   int                          // 事件日志向前滚动的条数，0表示跟随最新事件
+	events        []logEvent                   // 已记录的事件，最多保留EventLogSize条
+	eventStates   map[string]*targetEventState // 每个目标的事件检测状态
+	eventFile     *os.File                     // 事件日志文件，未配置时为nil
+	eventsMu      sync.Mutex                   // 保护事件日志相关字段的锁
+
+	// 底部状态栏
+	statusBar  *tview.TextView // 状态栏
+	resultRate rateCounter     // 最近几秒收到的探测结果数（不含界面处理不及时被丢弃的结果），由statsMu保护
 }
 
 // NewTUI 创建新的TUI实例
 func NewTUI(dataSource core.DataSource, targets []string, tuiConfig *Config, pingerConfig *pinger.Config) *TUI {
+	theme := tuiConfig.resolveTheme()
+	glyphs := resolveGlyphs(tuiConfig.Renderer, os.Getenv)
+
 	tui := &TUI{
 		app:              tview.NewApplication(),
-		chart:            tview.NewTextView(),
 		dataSource:       dataSource,
-		targets:          targets,
+		targets:          append([]string(nil), targets...),
 		tuiConfig:        tuiConfig,
+		pingerConfig:     pingerConfig.Clone(),
 		timeoutThreshold: tuiConfig.GetTimeoutThreshold(pingerConfig.Timeout),
-		statsData:        make(map[string]*core.Stats),
+		statsData:        make(map[string]*targetStats),
+		colorIndex:       make(map[string]int),
+		removedTargets:   make(map[string]time.Time),
+		resetTimes:       make(map[string]time.Time),
+		hiddenTargets:    make(map[string]bool),
+		eventStates:      make(map[string]*targetEventState),
+		bindings:         defaultKeyBindings(),
+		commands:         defaultCommands(),
 		stopChan:         make(chan struct{}),
 		doneChan:         make(chan struct{}),
 		testMode:         false,
+		logScale:         tuiConfig.LogScale,
+		relativeTime:     tuiConfig.RelativeTime,
+		sortColumn:       tuiConfig.SortColumn,
+		theme:            theme,
+		themeColors:      theme.semanticColors(),
+		glyphs:           glyphs,
+		sortDescending:   tuiConfig.SortDescending,
 		selectedRow:      -1,         // 默认全选状态
 		startTime:        time.Now(), // 记录程序启动时间
 	}
+	tui.assignColors()
+
+	// 界面组件按主题着色，需要在theme设置之后创建
+	tui.chart = tui.newTextView()
+	tui.histogramView = tui.newTextView()
+	tui.eventView = tui.newTextView()
+	tui.statusBar = tui.newTextView()
 
 	tui.setupUI()
 	tui.setupKeyBindings()
+	tui.setupMouse()
 
 	return tui
 }
 
 // NewTUIForTest 创建用于测试的TUI实例（不初始化图形组件）
 func NewTUIForTest(dataSource core.DataSource, targets []string, tuiConfig *Config, pingerConfig *pinger.Config) *TUI {
-	return &TUI{
+	theme := tuiConfig.resolveTheme()
+	glyphs, ok := glyphsByName(tuiConfig.Renderer)
+	if !ok {
+		glyphs = brailleGlyphs // 自动选择时测试结果不依赖终端环境
+	}
+	tui := &TUI{
 		app:              tview.NewApplication(), // 创建一个应用实例，但不会运行
 		dataSource:       dataSource,
-		targets:          targets,
+		targets:          append([]string(nil), targets...),
 		tuiConfig:        tuiConfig,
+		pingerConfig:     pingerConfig.Clone(),
 		timeoutThreshold: tuiConfig.GetTimeoutThreshold(pingerConfig.Timeout),
-		statsData:        make(map[string]*core.Stats),
+		statsData:        make(map[string]*targetStats),
+		colorIndex:       make(map[string]int),
+		removedTargets:   make(map[string]time.Time),
+		resetTimes:       make(map[string]time.Time),
+		hiddenTargets:    make(map[string]bool),
+		eventStates:      make(map[string]*targetEventState),
+		bindings:         defaultKeyBindings(),
+		commands:         defaultCommands(),
 		stopChan:         make(chan struct{}),
 		doneChan:         make(chan struct{}),
 		testMode:         true,
+		logScale:         tuiConfig.LogScale,
+		relativeTime:     tuiConfig.RelativeTime,
+		sortColumn:       tuiConfig.SortColumn,
+		theme:            theme,
+		themeColors:      theme.semanticColors(),
+		glyphs:           glyphs,
+		sortDescending:   tuiConfig.SortDescending,
 		selectedRow:      -1,         // 默认全选状态
 		startTime:        time.Now(), // 记录程序启动时间
 	}
+	tui.assignColors()
+	return tui
 }
 
 // Run 启动TUI界面
 func (t *TUI) Run() error {
+	return t.RunContext(context.Background())
+}
+
+// RunContext 启动TUI界面，ctx被取消时自动退出
+func (t *TUI) RunContext(ctx context.Context) error {
+	if err := t.openEventLog(); err != nil {
+		return fmt.Errorf("打开事件日志文件失败: %w", err)
+	}
+	defer t.closeEventLog()
+
 	// 启动数据源
-	t.dataSource.Start()
+	if err := t.dataSource.Start(ctx); err != nil {
+		return fmt.Errorf("启动数据源失败: %w", err)
+	}
+
+	// ctx取消时退出界面
+	go func() {
+		select {
+		case <-ctx.Done():
+			t.Stop()
+		case <-t.stopChan:
+		}
+	}()
 
 	// 启动数据处理goroutine
 	go t.processData()
@@ -104,12 +243,9 @@ func (t *TUI) Run() error {
 // Stop 停止TUI界面
 func (t *TUI) Stop() {
 	// 先发送停止信号，让processData退出
-	select {
-	case <-t.stopChan:
-		// stopChan已经关闭，避免重复关闭
-	default:
+	t.stopOnce.Do(func() {
 		close(t.stopChan)
-	}
+	})
 
 	// 停止数据源
 	t.dataSource.Stop()
@@ -123,6 +259,7 @@ func (t *TUI) processData() {
 	defer close(t.doneChan)
 
 	dataChan := t.dataSource.DataStream()
+	eventChan := t.dataSource.Events()
 	uiTicker := time.NewTicker(t.tuiConfig.RefreshInterval)
 	defer uiTicker.Stop()
 
@@ -140,6 +277,14 @@ func (t *TUI) processData() {
 			}
 			t.handleDataUpdate(result)
 
+		case event, ok := <-eventChan:
+			if !ok {
+				// 事件通道关闭后不再监听
+				eventChan = nil
+				continue
+			}
+			t.handleSourceEvent(event)
+
 		case <-uiTicker.C:
 			t.handleUIRefresh()
 
@@ -164,6 +309,36 @@ func (t *TUI) forceInitialDraw() {
 // handleDataUpdate 处理数据更新
 func (t *TUI) handleDataUpdate(result core.PingResult) {
 	t.updateStatsWithTime(result)
+	t.recordEvents(t.detectEvents(result))
+}
+
+// handleSourceEvent 处理数据源事件
+func (t *TUI) handleSourceEvent(event core.SourceEvent) {
+	if event.Identifier != "" {
+		t.statsMu.RLock()
+		stale := t.isStaleResult(event.Identifier, event.Time)
+		t.statsMu.RUnlock()
+		if stale {
+			return
+		}
+	}
+
+	t.recordEvents(t.sourceEvents(event))
+
+	// 地址事件不代表健康状态变化
+	if event.Address == "" {
+		t.updateHealth(event)
+	}
+
+	if event.Err != nil && !t.testMode && t.app != nil {
+		message := fmt.Sprintf("[red]%s: %s[white]", tview.Escape(event.Identifier), tview.Escape(event.Err.Error()))
+		if event.Identifier == "" {
+			message = fmt.Sprintf("[red]数据源错误: %s[white]", tview.Escape(event.Err.Error()))
+		}
+		t.safeUIUpdate(func() {
+			t.setMessage(message)
+		})
+	}
 }
 
 // handleUIRefresh 处理UI刷新
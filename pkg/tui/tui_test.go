@@ -1,12 +1,21 @@
 package tui
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/Kevin-Rudy/goping/pkg/core"
 	"github.com/Kevin-Rudy/goping/pkg/pinger"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
 )
 
 // mockDataSource 模拟数据源，用于测试
@@ -26,8 +35,17 @@ func (m *mockDataSource) DataStream() <-chan core.PingResult {
 	return m.dataChan
 }
 
-func (m *mockDataSource) Start() {
+func (m *mockDataSource) Events() <-chan core.SourceEvent {
+	return nil
+}
+
+func (m *mockDataSource) Start(ctx context.Context) error {
 	m.started = true
+	return nil
+}
+
+func (m *mockDataSource) Health(identifier string) core.TargetHealth {
+	return core.HealthUnknown
 }
 
 func (m *mockDataSource) Stop() {
@@ -513,3 +531,2027 @@ func BenchmarkDrawChart(b *testing.B) {
 		tui.drawSingleTargetChart("test.com", 80, 20)
 	}
 }
+
+// TestExtraReplyCounting 测试重复、乱序和迟到回复的计数
+func TestExtraReplyCounting(t *testing.T) {
+	mock := newMockDataSource()
+	targets := []string{"test.com"}
+	tui := NewTUIForTest(mock, targets, DefaultConfig(), pinger.DefaultConfig())
+
+	now := time.Now()
+	results := []core.PingResult{
+		{Identifier: "test.com", Latency: 10, SendTime: now, Seq: 1},
+		{Identifier: "test.com", Latency: 11, SendTime: now, Seq: 1, Kind: core.ReplyDuplicate},
+		{Identifier: "test.com", Latency: 12, SendTime: now, Seq: 3, OutOfOrder: true},
+		{Identifier: "test.com", Latency: 3500, SendTime: now, Seq: 2, Kind: core.ReplyLate, OutOfOrder: true},
+	}
+	for _, result := range results {
+		tui.updateStatsWithTime(result)
+	}
+
+	stats := tui.statsData["test.com"]
+	if stats.PacketsSent != 2 || stats.PacketsRecv != 2 {
+		t.Errorf("Extra replies should not count as probes, got sent=%d recv=%d", stats.PacketsSent, stats.PacketsRecv)
+	}
+	if stats.Duplicates != 1 {
+		t.Errorf("Expected 1 duplicate, got %d", stats.Duplicates)
+	}
+	if stats.Reordered != 2 {
+		t.Errorf("Expected 2 reordered, got %d", stats.Reordered)
+	}
+	if stats.LateReplies != 1 {
+		t.Errorf("Expected 1 late reply, got %d", stats.LateReplies)
+	}
+	if stats.Summary["重复"] != "1" || stats.Summary["乱序"] != "2" {
+		t.Errorf("Unexpected summary: %v", stats.Summary)
+	}
+}
+
+// TestLateReplyAccounting 测试迟到回复不计为丢包，并保留真实延迟
+func TestLateReplyAccounting(t *testing.T) {
+	mock := newMockDataSource()
+	targets := []string{"sat.link"}
+	tui := NewTUIForTest(mock, targets, DefaultConfig(), pinger.DefaultConfig())
+
+	sendTime := time.Now()
+	tui.updateStatsWithTime(core.PingResult{Identifier: "sat.link", Latency: math.NaN(), SendTime: sendTime, Seq: 1})
+	tui.updateStatsWithTime(core.PingResult{Identifier: "sat.link", Latency: 3600, SendTime: sendTime, Seq: 1, Kind: core.ReplyLate})
+
+	stats := tui.statsData["sat.link"]
+	if stats.LateReplies != 1 {
+		t.Errorf("Expected 1 late reply, got %d", stats.LateReplies)
+	}
+	if stats.History[0].Status != core.PointLate || stats.History[0].Value != 3600 {
+		t.Errorf("Expected timeout point to become late with real latency, got %+v", stats.History[0])
+	}
+	if stats.Summary["丢包率"] != "0.0%" {
+		t.Errorf("Late reply should not count as loss, got %s", stats.Summary["丢包率"])
+	}
+	if stats.Summary["t/o"] != "1" || stats.Summary["迟到"] != "1" {
+		t.Errorf("Unexpected summary: %v", stats.Summary)
+	}
+	if stats.MaxLatency != 3600 {
+		t.Errorf("Expected late latency in max, got %f", stats.MaxLatency)
+	}
+}
+
+// mockTargetSource 支持运行时增删目标的模拟数据源
+type mockTargetSource struct {
+	*mockDataSource
+	mu        sync.Mutex // 保护added和removed，命令在后台增删目标
+	added     []string
+	removed   []string
+	intervals []string
+}
+
+func (m *mockTargetSource) AddTarget(identifier string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.added = append(m.added, identifier)
+	return nil
+}
+
+func (m *mockTargetSource) RemoveTarget(identifier string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.removed = append(m.removed, identifier)
+	return nil
+}
+
+// removedCount 返回数据源收到的移除请求数
+func (m *mockTargetSource) removedCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.removed)
+}
+
+func (m *mockTargetSource) SetInterval(identifier string, interval time.Duration) error {
+	if interval < 10*time.Millisecond {
+		return fmt.Errorf("interval too small")
+	}
+	m.intervals = append(m.intervals, fmt.Sprintf("%s=%v", identifier, interval))
+	return nil
+}
+
+// TestRuntimeTargetManagement 测试运行时增删目标时保留已有目标的颜色和统计
+func TestRuntimeTargetManagement(t *testing.T) {
+	mock := &mockTargetSource{mockDataSource: newMockDataSource()}
+	targets := []string{"a.com", "b.com", "c.com"}
+	tui := NewTUIForTest(mock, targets, DefaultConfig(), pinger.DefaultConfig())
+
+	for _, target := range targets {
+		tui.updateStatsWithTime(core.PingResult{Identifier: target, Latency: 10, SendTime: time.Now()})
+	}
+	colorC := tui.getTargetColor("c.com")
+
+	if err := tui.removeTarget("b.com"); err != nil {
+		t.Fatalf("removeTarget failed: %v", err)
+	}
+	if err := tui.addTarget("d.com"); err != nil {
+		t.Fatalf("addTarget failed: %v", err)
+	}
+
+	if tui.getTargetColor("c.com") != colorC {
+		t.Error("Existing target color should be preserved after removal")
+	}
+	if tui.getTargetColor("d.com") == colorC || tui.getTargetColor("d.com") == tui.getTargetColor("a.com") {
+		t.Error("Added target should get a new color")
+	}
+	if _, exists := tui.statsData["b.com"]; exists {
+		t.Error("Removed target stats should be discarded")
+	}
+	if tui.statsData["c.com"].PacketsSent != 1 {
+		t.Error("Existing target stats should be preserved")
+	}
+
+	// 已移除目标的残留结果应被忽略
+	tui.updateStatsWithTime(core.PingResult{Identifier: "b.com", Latency: 10, SendTime: time.Now()})
+	if _, exists := tui.statsData["b.com"]; exists {
+		t.Error("Late results of removed target should be ignored")
+	}
+
+	tui.updateStatsWithTime(core.PingResult{Identifier: "d.com", Latency: 10, SendTime: time.Now()})
+	tui.updateIdentifiersForTest()
+	expected := []string{"a.com", "c.com", "d.com"}
+	if len(tui.identifiers) != len(expected) {
+		t.Fatalf("Expected identifiers %v, got %v", expected, tui.identifiers)
+	}
+	for i, identifier := range expected {
+		if tui.identifiers[i] != identifier {
+			t.Errorf("Expected identifiers %v, got %v", expected, tui.identifiers)
+			break
+		}
+	}
+
+	if len(mock.added) != 1 || len(mock.removed) != 1 {
+		t.Errorf("Expected data source to be notified, got added=%v removed=%v", mock.added, mock.removed)
+	}
+
+	// 重新添加后，移除前发出的探测结果仍应被丢弃，重新添加后发出的结果正常计入
+	sentBeforeRemoval := tui.removedTargets["b.com"].Add(-time.Millisecond)
+	if err := tui.addTarget("b.com"); err != nil {
+		t.Fatalf("addTarget failed: %v", err)
+	}
+	tui.updateStatsWithTime(core.PingResult{Identifier: "b.com", Latency: 500, SendTime: sentBeforeRemoval})
+	tui.handleSourceEvent(core.SourceEvent{Time: sentBeforeRemoval, Identifier: "b.com", Health: core.HealthDown})
+	if _, exists := tui.statsData["b.com"]; exists {
+		t.Error("Results sent before removal should be ignored after the target is re-added")
+	}
+	tui.updateStatsWithTime(core.PingResult{Identifier: "b.com", Latency: 10, SendTime: time.Now()})
+	if stats := tui.statsData["b.com"]; stats == nil || stats.PacketsSent != 1 || stats.MaxLatency != 10 {
+		t.Errorf("Expected only the new result for re-added target, got %+v", stats)
+	}
+}
+
+// TestTargetManagementUnsupported 测试数据源不支持增删目标时返回错误
+func TestTargetManagementUnsupported(t *testing.T) {
+	tui := NewTUIForTest(newMockDataSource(), []string{"a.com"}, DefaultConfig(), pinger.DefaultConfig())
+
+	if err := tui.addTarget("b.com"); err == nil {
+		t.Error("Expected error when data source does not support adding targets")
+	}
+	if err := tui.removeTarget("a.com"); err == nil {
+		t.Error("Expected error when data source does not support removing targets")
+	}
+}
+
+// TestSourceEventHealth 测试数据源事件更新目标健康状态
+func TestSourceEventHealth(t *testing.T) {
+	tui := NewTUIForTest(newMockDataSource(), []string{"test.com"}, DefaultConfig(), pinger.DefaultConfig())
+
+	tui.handleSourceEvent(core.SourceEvent{Time: time.Now(), Identifier: "test.com", Health: core.HealthDown})
+	if health := tui.statsData["test.com"].Summary["状态"]; health != "中断" {
+		t.Errorf("Expected health summary '中断', got %q", health)
+	}
+
+	// 数据源整体事件不对应任何目标
+	tui.handleSourceEvent(core.SourceEvent{Time: time.Now(), Health: core.HealthError})
+	if len(tui.statsData) != 1 {
+		t.Errorf("Source-level event should not create target stats, got %d", len(tui.statsData))
+	}
+}
+
+// TestMixedIntervalAlignment 测试不同探测间隔的目标按各自间隔进行时间对齐
+func TestMixedIntervalAlignment(t *testing.T) {
+	pingerConfig := pinger.DefaultConfig()
+	pingerConfig.SetTargetOverride("fast.gw", 100*time.Millisecond, 0)
+	pingerConfig.SetTargetOverride("slow.wan", time.Second, 5*time.Second)
+	tuiConfig := DefaultConfig()
+	tuiConfig.HistoryRetention = 0 // 只保留一个时间窗口
+	tui := NewTUIForTest(newMockDataSource(), []string{"fast.gw", "slow.wan"}, tuiConfig, pingerConfig)
+
+	base := time.Now()
+
+	// 慢速目标：超时后4秒恢复，应按1秒步长插值3个点，而不是按200ms网格插值
+	tui.updateStatsWithTime(core.PingResult{Identifier: "slow.wan", Latency: math.NaN(), SendTime: base})
+	tui.updateStatsWithTime(core.PingResult{Identifier: "slow.wan", Latency: 50, SendTime: base.Add(4 * time.Second)})
+
+	slow := tui.statsData["slow.wan"]
+	if len(slow.History) != 5 {
+		t.Fatalf("Expected 5 points for slow target (1 timeout + 3 interpolated + 1 success), got %d", len(slow.History))
+	}
+	if gap := slow.History[2].Timestamp.Sub(slow.History[1].Timestamp); gap != time.Second {
+		t.Errorf("Expected interpolation step of 1s, got %v", gap)
+	}
+
+	// 快速目标：时间窗口为30秒，100ms间隔需要300个点才能覆盖完整窗口
+	for i := 0; i < 400; i++ {
+		tui.updateStatsWithTime(core.PingResult{
+			Identifier: "fast.gw",
+			Latency:    1,
+			SendTime:   base.Add(time.Duration(i) * 100 * time.Millisecond),
+		})
+	}
+	if points := len(tui.statsData["fast.gw"].History); points != 300 {
+		t.Errorf("Expected fast target to keep 300 points covering the window, got %d", points)
+	}
+}
+
+// TestPauseAndPan 测试暂停、回看平移和回到实时视图
+func TestPauseAndPan(t *testing.T) {
+	tui := NewTUIForTest(newMockDataSource(), []string{"test.com"}, DefaultConfig(), pinger.DefaultConfig())
+	window := tui.windowDuration()
+
+	// 模拟程序已运行5分钟
+	tui.startTime = time.Now().Add(-5 * time.Minute)
+
+	tui.togglePause()
+	if !tui.paused {
+		t.Fatal("Expected view to be paused")
+	}
+	start, end := tui.getTimeWindow()
+	time.Sleep(20 * time.Millisecond)
+	start2, end2 := tui.getTimeWindow()
+	if !start.Equal(start2) || !end.Equal(end2) {
+		t.Error("Paused window should not follow current time")
+	}
+
+	// 向过去平移
+	tui.panView(-1)
+	_, panned := tui.getTimeWindow()
+	if expected := end.Add(-time.Duration(float64(window) * panStepRatio)); !panned.Equal(expected) {
+		t.Errorf("Expected window end %v after panning left, got %v", expected, panned)
+	}
+	if tui.viewStatus() == "" {
+		t.Error("Expected view status while paused")
+	}
+
+	// 不能平移到程序启动之前
+	for i := 0; i < 100; i++ {
+		tui.panView(-1)
+	}
+	if start, _ := tui.getTimeWindow(); start.Before(tui.startTime) {
+		t.Errorf("Window should not start before program start, got %v", start)
+	}
+
+	// 不能平移到实时窗口之后
+	for i := 0; i < 100; i++ {
+		tui.panView(1)
+	}
+	if _, end := tui.getTimeWindow(); end.After(time.Now()) {
+		t.Error("Window should not end in the future")
+	}
+
+	tui.resumeLive()
+	if tui.paused || tui.viewStatus() != "" {
+		t.Error("Expected live view after resume")
+	}
+}
+
+// TestHistoryRetention 测试历史数据按保留时长保留，超出部分被移除
+func TestHistoryRetention(t *testing.T) {
+	tuiConfig := DefaultConfig()
+	tuiConfig.HistoryRetention = time.Minute
+	tui := NewTUIForTest(newMockDataSource(), []string{"test.com"}, tuiConfig, pinger.DefaultConfig())
+
+	base := time.Now()
+	for i := 0; i < 120; i++ {
+		tui.updateStatsWithTime(core.PingResult{
+			Identifier: "test.com",
+			Latency:    10,
+			SendTime:   base.Add(time.Duration(i) * time.Second),
+		})
+	}
+
+	history := tui.statsData["test.com"].History
+	if span := history[len(history)-1].Timestamp.Sub(history[0].Timestamp); span > time.Minute {
+		t.Errorf("Expected history span within retention, got %v", span)
+	}
+	if windowPoints := int(tui.windowDuration() / time.Second); len(history) <= windowPoints {
+		t.Errorf("Expected history beyond the visible window to be retained, got %d points", len(history))
+	}
+}
+
+// TestZoomLevels 测试缩放级别的切换
+func TestZoomLevels(t *testing.T) {
+	tui := NewTUIForTest(newMockDataSource(), []string{"test.com"}, DefaultConfig(), pinger.DefaultConfig())
+
+	base := tui.baseWindow()
+	expected := []time.Duration{base, time.Minute, 5 * time.Minute, time.Hour, 24 * time.Hour}
+	windows := tui.zoomWindows()
+	if len(windows) != len(expected) {
+		t.Fatalf("Expected %d zoom levels, got %v", len(expected), windows)
+	}
+	for i := range expected {
+		if windows[i] != expected[i] {
+			t.Errorf("Expected zoom level %d to be %v, got %v", i, expected[i], windows[i])
+		}
+	}
+
+	tui.zoomIn()
+	if tui.windowDuration() != base {
+		t.Errorf("Expected zooming in at the default window to be a no-op, got %v", tui.windowDuration())
+	}
+	for i := 0; i < 10; i++ {
+		tui.zoomOut()
+	}
+	if tui.windowDuration() != 24*time.Hour {
+		t.Errorf("Expected widest zoom level 24h, got %v", tui.windowDuration())
+	}
+	tui.zoomIn()
+	if tui.windowDuration() != time.Hour {
+		t.Errorf("Expected 1h after zooming in, got %v", tui.windowDuration())
+	}
+	if status := tui.viewStatus(); !strings.Contains(status, "1h") {
+		t.Errorf("Expected view status to show zoom level, got %q", status)
+	}
+
+	// 原始历史的保留时长不随缩放级别变化
+	if tui.retention() != tui.tuiConfig.HistoryRetention {
+		t.Errorf("Expected raw retention to be independent of zoom level, got %v", tui.retention())
+	}
+}
+
+// TestDownsampling 测试长期历史的聚合和降采样，尖峰和丢包在粗粒度下仍然可见
+func TestDownsampling(t *testing.T) {
+	tuiConfig := DefaultConfig()
+	tui := NewTUIForTest(newMockDataSource(), []string{"test.com"}, tuiConfig, pinger.DefaultConfig())
+
+	end := time.Now()
+	start := end.Add(-2 * time.Hour)
+	for i := 0; i < 7200; i++ {
+		latency := 10.0
+		switch i {
+		case 4200, 7080: // 50分钟前和2分钟前的尖峰
+			latency = 500
+		case 4800: // 40分钟前的一次丢包
+			latency = math.NaN()
+		}
+		tui.updateStatsWithTime(core.PingResult{
+			Identifier: "test.com",
+			Latency:    latency,
+			SendTime:   start.Add(time.Duration(i) * time.Second),
+		})
+	}
+
+	stats := tui.statsData["test.com"]
+	if span := stats.History[len(stats.History)-1].Timestamp.Sub(stats.History[0].Timestamp); span > tuiConfig.HistoryRetention {
+		t.Errorf("Expected raw history within retention, got %v", span)
+	}
+	if len(stats.rollups) < 700 || len(stats.rollups) > 730 {
+		t.Errorf("Expected about 720 rollup buckets for 2h of data, got %d", len(stats.rollups))
+	}
+
+	// 1小时窗口超出原始历史，使用聚合历史
+	columns := 100
+	points := tui.chartPoints(stats, end.Add(-time.Hour), end, columns)
+	if len(points) > columns*3 {
+		t.Errorf("Expected at most %d points after downsampling, got %d", columns*3, len(points))
+	}
+	var maxValue float64
+	var lost int
+	for _, point := range points {
+		if math.IsNaN(point.Value) {
+			lost++
+		} else if point.Value > maxValue {
+			maxValue = point.Value
+		}
+	}
+	if maxValue != 500 {
+		t.Errorf("Expected old spike to survive downsampling, max %v", maxValue)
+	}
+	if lost != 1 {
+		t.Errorf("Expected exactly one lost bucket, got %d", lost)
+	}
+
+	// 含尖峰的列输出最小值、最大值两个范围点和一个平均值点，平均值参与折线连接
+	var spikeColumn []core.DataPoint
+	for i, point := range points {
+		if point.Status == core.PointRange && point.Value == 500 {
+			spikeColumn = points[i-1 : i+2]
+			break
+		}
+	}
+	if len(spikeColumn) != 3 || spikeColumn[0].Status != core.PointRange || spikeColumn[0].Value != 10 ||
+		spikeColumn[2].Status != core.PointSuccess || spikeColumn[2].Value <= 10 || spikeColumn[2].Value >= 500 {
+		t.Errorf("Expected min/max range points followed by the bucket average, got %+v", spikeColumn)
+	}
+
+	// 5分钟窗口在原始历史范围内，点数超过列数时降采样原始数据
+	points = tui.chartPoints(stats, end.Add(-5*time.Minute), end, columns)
+	if len(points) > columns*3 {
+		t.Errorf("Expected raw points to be downsampled, got %d", len(points))
+	}
+	maxValue = 0
+	for _, point := range points {
+		if point.Value > maxValue {
+			maxValue = point.Value
+		}
+	}
+	if maxValue != 500 {
+		t.Errorf("Expected recent spike to survive downsampling, max %v", maxValue)
+	}
+
+	// 点数不超过列数时直接返回原始历史
+	points = tui.chartPoints(stats, end.Add(-30*time.Second), end, columns)
+	if len(points) != len(stats.History) {
+		t.Errorf("Expected raw history when not downsampling, got %d points", len(points))
+	}
+
+	// 刚启动时聚合桶的起点早于第一个数据点，但不应因此改用聚合历史
+	fresh := NewTUIForTest(newMockDataSource(), []string{"test.com"}, tuiConfig, pinger.DefaultConfig())
+	for i := 0; i < 5; i++ {
+		fresh.updateStatsWithTime(core.PingResult{Identifier: "test.com", Latency: 10, SendTime: end.Add(time.Duration(i-5) * time.Second)})
+	}
+	freshStats := fresh.statsData["test.com"]
+	points = fresh.chartPoints(freshStats, end.Add(-30*time.Second), end, columns)
+	if len(points) != len(freshStats.History) {
+		t.Errorf("Expected raw history right after start, got %d points", len(points))
+	}
+}
+
+// TestRollupBucketDistribution 测试聚合桶在合并后保留延迟分布
+func TestRollupBucketDistribution(t *testing.T) {
+	var a, b rollupBucket
+	for i := 0; i < 9; i++ {
+		a.add(10)
+	}
+	a.add(math.NaN())
+	b.add(100)
+	b.add(0.5)
+
+	var merged rollupBucket
+	merged.merge(a)
+	merged.merge(b)
+	if merged.count != 11 || merged.lost != 1 || merged.min != 0.5 || merged.max != 100 {
+		t.Errorf("Unexpected merged bucket: %+v", merged)
+	}
+
+	// 分布只保存有样本的桶区间，向两端扩展后各桶计数不变
+	histogram := merged.histogram()
+	if histogram.total != 11 || histogram.counts[latencyBucketIndex(10)] != 9 ||
+		histogram.counts[latencyBucketIndex(100)] != 1 || histogram.counts[latencyBucketIndex(0.5)] != 1 {
+		t.Errorf("Expected merged distribution 9/1/1, got first=%d counts=%v", merged.first, merged.counts)
+	}
+	if len(merged.counts) != latencyBucketIndex(100)-latencyBucketIndex(0.5)+1 {
+		t.Errorf("Expected counts to span only the occupied buckets, got %d", len(merged.counts))
+	}
+
+	// 合并不应修改被合并的桶
+	if len(a.counts) != 1 || a.histogram().total != 9 {
+		t.Error("Merge should not modify the source bucket")
+	}
+}
+
+// TestHeatmap 测试热力图视图的切换和渲染
+func TestHeatmap(t *testing.T) {
+	targets := []string{"a.com", "b.com"}
+	tui := NewTUIForTest(newMockDataSource(), targets, DefaultConfig(), pinger.DefaultConfig())
+
+	tui.cycleChartMode()
+	if tui.chartMode != chartModeHeatmap {
+		t.Fatalf("Expected heatmap mode after cycling, got %v", tui.chartMode)
+	}
+
+	now := time.Now()
+	tui.startTime = now.Add(-time.Minute) // 越过填充阶段，使窗口以当前时间结尾
+	for i := 0; i < 20; i++ {
+		sendTime := now.Add(time.Duration(i-20) * time.Second)
+		tui.updateStatsWithTime(core.PingResult{Identifier: "a.com", Latency: 10 + float64(i%2)*40, SendTime: sendTime})
+		latency := 20.0
+		if i == 10 {
+			latency = math.NaN()
+		}
+		tui.updateStatsWithTime(core.PingResult{Identifier: "b.com", Latency: latency, SendTime: sendTime})
+	}
+	tui.updateIdentifiersForTest()
+
+	output := tui.drawHeatmap(tui.chartTargets(), 80, 20)
+	lines := strings.Split(output, "\n")
+	if len(lines) != 20 {
+		t.Errorf("Expected 20 lines, got %d", len(lines))
+	}
+	for _, target := range targets {
+		if !strings.Contains(output, target) {
+			t.Errorf("Expected heatmap to contain strip for %s", target)
+		}
+	}
+	if !strings.ContainsAny(output, string(heatmapShades)) {
+		t.Error("Expected heatmap to contain shaded cells")
+	}
+
+	// 单选时只绘制选中的目标
+	tui.selectedRow = 1
+	output = tui.drawHeatmap(tui.chartTargets(), 80, 20)
+	if strings.Contains(output, "a.com") || !strings.Contains(output, "b.com") {
+		t.Error("Expected heatmap to only contain the selected target")
+	}
+
+	// 粗粒度下按聚合桶保留的延迟分布统计，而不是只统计每列的最小/最大值
+	stats := newTargetStats("c.com")
+	var bucket rollupBucket
+	bucket.start = now.Add(-time.Hour)
+	for i := 0; i < 100; i++ {
+		latency := 10.0
+		if i%10 == 0 {
+			latency = 100
+		}
+		bucket.add(latency)
+	}
+	bucket.add(math.NaN())
+	stats.rollups = append(stats.rollups, bucket)
+	grid := tui.heatmapGrid(stats, now.Add(-2*time.Hour), now, 10, 100, 1, 10)
+	if grid.totals[0] != 101 || grid.lost[0] != 1 {
+		t.Errorf("Expected 101 probes with 1 lost in rollup column, got %d/%d", grid.totals[0], grid.lost[0])
+	}
+	if grid.counts[0][0] != 90 || grid.counts[0][9] != 10 {
+		t.Errorf("Expected rollup distribution 90/10 in lowest/highest rows, got %v", grid.counts[0])
+	}
+
+	// 浓度字符随样本占比递增
+	if heatmapShade(0, 4) != ' ' || heatmapShade(1, 4) != '░' || heatmapShade(4, 4) != '█' {
+		t.Error("Unexpected heatmap shading")
+	}
+}
+
+// TestLatencyHistogram 测试延迟直方图的分桶和分位数
+func TestLatencyHistogram(t *testing.T) {
+	h := newLatencyHistogram()
+	if !math.IsNaN(h.quantile(0.5)) {
+		t.Error("Expected NaN quantile for empty histogram")
+	}
+
+	// 双峰分布：90个10ms左右的样本，10个100ms左右的样本
+	for i := 0; i < 90; i++ {
+		h.add(10)
+	}
+	for i := 0; i < 10; i++ {
+		h.add(100)
+	}
+	h.add(math.NaN())
+
+	if h.total != 100 {
+		t.Errorf("Expected 100 samples, got %d", h.total)
+	}
+
+	// 分位数的相对误差应在一个桶宽度之内
+	within := func(got, want float64) bool {
+		return math.Abs(got-want)/want < 0.15
+	}
+	if p50 := h.quantile(0.5); !within(p50, 10) {
+		t.Errorf("Expected p50 near 10ms, got %v", p50)
+	}
+	if p90 := h.quantile(0.9); !within(p90, 10) {
+		t.Errorf("Expected p90 near 10ms, got %v", p90)
+	}
+	if p99 := h.quantile(0.99); !within(p99, 100) {
+		t.Errorf("Expected p99 near 100ms, got %v", p99)
+	}
+
+	// 超出范围的值计入首尾两个桶
+	h.add(0)
+	h.add(1e9)
+	if h.counts[0] != 1 || h.counts[len(h.counts)-1] != 1 {
+		t.Error("Expected out-of-range values to be clamped into edge buckets")
+	}
+
+	lower, upper := h.bucketBounds(0)
+	if lower != histogramMinLatency || upper <= lower {
+		t.Errorf("Unexpected bounds for first bucket: %v-%v", lower, upper)
+	}
+}
+
+// TestHistogram 测试延迟分布直方图，双峰分布的两个峰应分别出现
+func TestHistogram(t *testing.T) {
+	tui := NewTUIForTest(newMockDataSource(), []string{"test.com"}, DefaultConfig(), pinger.DefaultConfig())
+	now := time.Now()
+	tui.startTime = now.Add(-time.Minute)
+
+	// 旧样本在可见窗口之外，只计入全程统计
+	tui.updateStatsWithTime(core.PingResult{Identifier: "test.com", Latency: 1000, SendTime: now.Add(-50 * time.Second)})
+	for i := 0; i < 20; i++ {
+		latency := 10.0
+		if i%4 == 0 {
+			latency = 80
+		}
+		tui.updateStatsWithTime(core.PingResult{
+			Identifier: "test.com",
+			Latency:    latency,
+			SendTime:   now.Add(time.Duration(i-20) * time.Second),
+		})
+	}
+
+	stats := tui.statsData["test.com"]
+	if stats.distribution.total != 21 {
+		t.Errorf("Expected 21 samples in distribution, got %d", stats.distribution.total)
+	}
+
+	rows := histogramRows(stats.distribution, 30)
+	peaks := 0
+	for i, row := range rows {
+		if row.count > 0 && (i == 0 || rows[i-1].count == 0) {
+			peaks++
+		}
+	}
+	if peaks != 3 {
+		t.Errorf("Expected 3 separated clusters (10ms, 80ms, 1s), got %d", peaks)
+	}
+
+	output := tui.drawHistogram("test.com", histogramPanelWidth, 30)
+	for _, marker := range []string{"p50", "p90", "p99", "全程 n=21"} {
+		if !strings.Contains(output, marker) {
+			t.Errorf("Expected histogram to contain %q", marker)
+		}
+	}
+
+	tui.cycleHistogramScope()
+	output = tui.drawHistogram("test.com", histogramPanelWidth, 30)
+	if !strings.Contains(output, "窗口 n=20") {
+		t.Errorf("Expected window scope to exclude samples outside the window, got:\n%s", output)
+	}
+
+	if output := tui.drawHistogram("", histogramPanelWidth, 30); !strings.Contains(output, "选择目标") {
+		t.Errorf("Expected hint when no target is selected, got %q", output)
+	}
+}
+
+// TestBandChart 测试带状图的分列统计和渲染
+func TestBandChart(t *testing.T) {
+	tui := NewTUIForTest(newMockDataSource(), []string{"test.com"}, DefaultConfig(), pinger.DefaultConfig())
+	now := time.Now()
+	tui.startTime = now.Add(-time.Minute)
+
+	// 前10秒每秒5个样本：10、20、30、40、丢失；之后全部丢失
+	for i := 0; i < 50; i++ {
+		latency := float64(i%5+1) * 10
+		if i%5 == 4 || i >= 40 {
+			latency = math.NaN()
+		}
+		tui.updateStatsWithTime(core.PingResult{
+			Identifier: "test.com",
+			Latency:    latency,
+			SendTime:   now.Add(-20*time.Second + 100*time.Millisecond + time.Duration(i)*200*time.Millisecond),
+		})
+	}
+	tui.updateIdentifiersForTest()
+
+	// 每列1秒，样本从列的中间开始，避免落在列边界上
+	columns := tui.bandColumns(tui.statsData["test.com"], now.Add(-30*time.Second), now, 30)
+	var withData, allLost int
+	for _, column := range columns {
+		if column.count > 0 {
+			withData++
+			if column.min != 10 || column.max != 40 || column.median != 25 || column.lost != 1 {
+				t.Errorf("Unexpected band column: %+v", column)
+			}
+		} else if column.lost > 0 {
+			allLost++
+		}
+	}
+	if withData != 8 || allLost != 2 {
+		t.Errorf("Expected 8 columns with data and 2 fully lost, got %d and %d", withData, allLost)
+	}
+
+	// 粗粒度下中位数来自聚合桶保留的延迟分布，不受尖峰拉高的平均值影响
+	rolled := newTargetStats("rolled.com")
+	var bucket rollupBucket
+	bucket.start = now.Add(-time.Hour)
+	for i := 0; i < 10; i++ {
+		latency := 10.0
+		if i == 0 {
+			latency = 1000
+		}
+		bucket.add(latency)
+	}
+	rolled.rollups = append(rolled.rollups, bucket)
+	rolledColumns := tui.bandColumns(rolled, now.Add(-2*time.Hour), now, 1)
+	if median := rolledColumns[0].median; math.Abs(median-10)/10 > 0.15 {
+		t.Errorf("Expected rollup median near 10ms (mean is %v), got %v", bucket.avg(), median)
+	}
+
+	if lossColor(0, 5) != "[green]" || lossColor(1, 20) != "[yellow]" || lossColor(1, 5) != "[orange]" || lossColor(5, 5) != "[red]" {
+		t.Error("Unexpected loss colors")
+	}
+
+	tui.chartMode = chartModeBand
+	output := tui.drawBandChart(tui.chartTargets(), 80, 20)
+	if len(strings.Split(output, "\n")) != 20 {
+		t.Errorf("Expected 20 lines of band chart output")
+	}
+	for _, want := range []string{"test.com", "░", "[orange]", "[red]▀", "带状图"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected band chart to contain %q", want)
+		}
+	}
+}
+
+// TestLogScale 测试对数刻度：相差几个数量级的目标都能分开显示
+func TestLogScale(t *testing.T) {
+	tuiConfig := DefaultConfig()
+	tuiConfig.LogScale = true
+	tui := NewTUIForTest(newMockDataSource(), []string{"lan", "wan"}, tuiConfig, pinger.DefaultConfig())
+	if !tui.logScale {
+		t.Fatal("Expected log scale to be enabled from config")
+	}
+
+	now := time.Now()
+	points := map[string][]core.DataPoint{
+		"lan": {{Timestamp: now.Add(-2 * time.Second), Value: 1, Status: core.PointSuccess}},
+		"wan": {{Timestamp: now.Add(-time.Second), Value: 250, Status: core.PointSuccess}},
+	}
+
+	minVal, maxVal, _, errMsg := tui.calculateValueRange(points, now.Add(-time.Minute), now)
+	if errMsg != "" {
+		t.Fatalf("Unexpected error: %s", errMsg)
+	}
+	if minVal <= 0 || minVal >= 1 || maxVal <= 250 {
+		t.Errorf("Expected buffered positive range around [1, 250], got [%v, %v]", minVal, maxVal)
+	}
+
+	// 对数刻度下1ms和250ms在纵轴上大致对称分布，线性刻度下1ms被压到底部
+	logLan := tui.normalizeValue(1, minVal, maxVal)
+	if logLan < 0.01 || logLan > 0.1 {
+		t.Errorf("Expected 1ms to sit clearly above the bottom on log scale, got %v", logLan)
+	}
+	if mid := tui.denormalizeValue(0.5, minVal, maxVal); mid < 10 || mid > 25 {
+		t.Errorf("Expected log scale midpoint near the geometric mean, got %v", mid)
+	}
+	if back := tui.denormalizeValue(tui.normalizeValue(42, minVal, maxVal), minVal, maxVal); math.Abs(back-42) > 1e-9 {
+		t.Errorf("Expected denormalize to invert normalize, got %v", back)
+	}
+
+	// 对数刻度的Y轴标签取10的整数次幂，并标在该数值所在的行
+	labels := tui.yAxisLabels(minVal, maxVal, 20)
+	var labelValues []string
+	for row := 0; row < 20; row++ {
+		if label, ok := labels[row]; ok {
+			labelValues = append(labelValues, label)
+		}
+	}
+	if strings.Join(labelValues, ",") != "100.0ms,10.0ms,1.0ms" {
+		t.Errorf("Expected decade labels on log scale, got %v", labelValues)
+	}
+	tenRow := int((1-tui.normalizeValue(10, minVal, maxVal))*float64(20*tui.glyphs.cellHeight-1)) / tui.glyphs.cellHeight
+	if labels[tenRow] != "10.0ms" {
+		t.Errorf("Expected 10ms label on row %d, got %v", tenRow, labels)
+	}
+
+	// 范围不足一个数量级时使用1-2-5序列
+	if ticks := logTicks(15, 120); len(ticks) != 3 || ticks[0] != 20 || ticks[1] != 50 || ticks[2] != 100 {
+		t.Errorf("Expected 1-2-5 ticks for narrow range, got %v", ticks)
+	}
+
+	tui.toggleLogScale()
+	linMin, linMax, _, _ := tui.calculateValueRange(points, now.Add(-time.Minute), now)
+	if linLan := tui.normalizeValue(1, linMin, linMax); linLan > logLan {
+		t.Errorf("Expected 1ms to be flattened on linear scale (%v) compared to log scale (%v)", linLan, logLan)
+	}
+}
+
+// TestThresholds 测试告警阈值的配置校验、表格颜色和图表参考线
+func TestThresholds(t *testing.T) {
+	invalid := []*Config{
+		NewConfigWithOptions(WithLatencyThresholds(-1, 0)),
+		NewConfigWithOptions(WithLatencyThresholds(150, 50)),
+		NewConfigWithOptions(WithLossThresholds(5, 101)),
+		NewConfigWithOptions(WithLossThresholds(20, 5)),
+	}
+	for i, config := range invalid {
+		if err := config.Validate(); err == nil {
+			t.Errorf("Expected invalid threshold config %d to be rejected", i)
+		}
+	}
+
+	tuiConfig := NewConfigWithOptions(WithLatencyThresholds(50, 150), WithLossThresholds(1, 10))
+	if err := tuiConfig.Validate(); err != nil {
+		t.Fatalf("Unexpected validation error: %v", err)
+	}
+	tui := NewTUIForTest(newMockDataSource(), []string{"ok", "warn", "crit"}, tuiConfig, pinger.DefaultConfig())
+
+	now := time.Now()
+	tui.startTime = now.Add(-time.Minute)
+	latencies := map[string]float64{"ok": 10, "warn": 80, "crit": 200}
+	for target, latency := range latencies {
+		for i := 0; i < 20; i++ {
+			value := latency
+			// crit目标丢包20%，warn目标丢包5%
+			if (target == "crit" && i%5 == 0) || (target == "warn" && i == 0) {
+				value = math.NaN()
+			}
+			tui.updateStatsWithTime(core.PingResult{Identifier: target, Latency: value, SendTime: now.Add(time.Duration(i-20) * time.Second)})
+		}
+	}
+
+	expected := map[string]tcell.Color{"ok": tcell.ColorWhite, "warn": tcell.ColorYellow, "crit": tcell.ColorRed}
+	for target, color := range expected {
+		stats := tui.statsData[target]
+		if got := tui.cellColor(stats, "平均延迟"); got != color {
+			t.Errorf("Expected latency cell of %s to be %v, got %v", target, color, got)
+		}
+		if got := tui.cellColor(stats, "丢包率"); got != color {
+			t.Errorf("Expected loss cell of %s to be %v, got %v", target, color, got)
+		}
+		if got := tui.cellColor(stats, "发送/接收"); got != tcell.ColorWhite {
+			t.Errorf("Expected cells without thresholds to stay white, got %v", got)
+		}
+	}
+
+	// 告警级别按最近的探测计算：长时间正常后延迟升高、丢包恢复后，颜色反映当前状态
+	recent := NewTUIForTest(newMockDataSource(), []string{"degraded", "recovered"}, tuiConfig, pinger.DefaultConfig())
+	for i := 0; i < 200; i++ {
+		sendTime := now.Add(time.Duration(i-200) * time.Second)
+		degraded, recovered := 10.0, math.NaN()
+		if i >= 200-thresholdSamples {
+			degraded, recovered = 200, 10
+		}
+		recent.updateStatsWithTime(core.PingResult{Identifier: "degraded", Latency: degraded, SendTime: sendTime})
+		recent.updateStatsWithTime(core.PingResult{Identifier: "recovered", Latency: recovered, SendTime: sendTime})
+	}
+	if stats := recent.statsData["degraded"]; stats.WelfordMean >= 50 || recent.cellColor(stats, "平均延迟") != tcell.ColorRed {
+		t.Errorf("Expected recent latency to be critical despite all-time mean %v", stats.WelfordMean)
+	}
+	if stats := recent.statsData["recovered"]; lossRate(stats) < 10 || recent.cellColor(stats, "丢包率") != tcell.ColorWhite {
+		t.Errorf("Expected recovered target to be normal despite all-time loss %.1f%%", lossRate(stats))
+	}
+
+	// 参考线只在值范围内绘制，严重阈值在警告阈值上方
+	rows := tui.thresholdRows(0, 100, 10)
+	if len(rows) != 1 {
+		t.Errorf("Expected only the warning line within [0, 100], got %v", rows)
+	}
+	rows = tui.thresholdRows(0, 300, 10)
+	var warnRow, critRow int
+	for row, color := range rows {
+		if color == "[yellow]" {
+			warnRow = row
+		} else {
+			critRow = row
+		}
+	}
+	if len(rows) != 2 || critRow >= warnRow {
+		t.Errorf("Expected critical line above warning line, got %v", rows)
+	}
+
+	tui.updateIdentifiersForTest()
+	if chart := tui.drawMultiTargetChart(80, 20); !strings.Contains(chart, thresholdLineChar) {
+		t.Errorf("Expected threshold lines on the chart, got:\n%s", chart)
+	}
+}
+
+// TestGridView 测试网格视图的布局和共享刻度
+func TestGridView(t *testing.T) {
+	if cols, rows, ok := gridLayout(1, 80, 20, 21, 6); !ok || cols != 1 || rows != 1 {
+		t.Errorf("Expected single cell layout, got %dx%d ok=%v", cols, rows, ok)
+	}
+	if cols, rows, ok := gridLayout(12, 160, 40, 21, 6); !ok || cols*rows < 12 || 160/cols < 21 || 40/rows < 6 {
+		t.Errorf("Unexpected layout for 12 targets: %dx%d ok=%v", cols, rows, ok)
+	}
+	if _, _, ok := gridLayout(12, 40, 10, 21, 6); ok {
+		t.Error("Expected layout to fail when cells would be too small")
+	}
+
+	var targets []string
+	for i := 0; i < 12; i++ {
+		targets = append(targets, fmt.Sprintf("host%02d", i))
+	}
+	tui := NewTUIForTest(newMockDataSource(), targets, DefaultConfig(), pinger.DefaultConfig())
+	now := time.Now()
+	tui.startTime = now.Add(-time.Minute)
+	for i, target := range targets {
+		for j := 0; j < 10; j++ {
+			tui.updateStatsWithTime(core.PingResult{
+				Identifier: target,
+				Latency:    float64(i+1)*10 + float64(j%3),
+				SendTime:   now.Add(time.Duration(j-10) * time.Second),
+			})
+		}
+	}
+	tui.updateIdentifiersForTest()
+
+	tui.toggleGridView()
+	output := tui.drawGridChart(tui.identifiers, 160, 40)
+	lines := strings.Split(output, "\n")
+	if len(lines) > 40 {
+		t.Errorf("Expected at most 40 lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if width := tview.TaggedStringWidth(line); width > 160 {
+			t.Errorf("Expected grid lines to fit in 160 columns, got %d", width)
+			break
+		}
+	}
+	for _, target := range targets {
+		if !strings.Contains(output, target) {
+			t.Errorf("Expected grid to contain a cell for %s", target)
+		}
+	}
+
+	// 共享刻度时所有小图的最大刻度相同，即最大延迟目标的刻度
+	countLabel := func(output, label string) int {
+		return strings.Count(output, label+"[white] [gray]│")
+	}
+	sharedTop := formatLatency(122 * (1 + tui.tuiConfig.ValueBufferRatio))
+	if countLabel(output, sharedTop) > 1 {
+		t.Errorf("Expected independent scales by default")
+	}
+	tui.toggleGridSharedScale()
+	output = tui.drawGridChart(tui.identifiers, 160, 40)
+	if count := countLabel(output, sharedTop); count != len(targets) {
+		t.Errorf("Expected all %d cells to share top label %s, got %d", len(targets), sharedTop, count)
+	}
+
+	// 每个小图记录自己在网格中的绘图区，鼠标所在的小图决定光标位置
+	tui.plotAreas = nil
+	tui.drawGridChart(tui.identifiers, 160, 40)
+	if len(tui.plotAreas) != len(targets) {
+		t.Fatalf("Expected one plot area per cell, got %d", len(tui.plotAreas))
+	}
+	last := tui.plotAreas[len(tui.plotAreas)-1]
+	if last.left < 80 || last.top < 20 {
+		t.Errorf("Expected the last cell's plot area to be offset into the grid, got %+v", last)
+	}
+	if area, ok := tui.plotAreaAt(last.left+last.width-1, last.top); !ok || area != last {
+		t.Errorf("Expected hit test to find the cell under the pointer, got %+v", area)
+	}
+	if !tui.setCursorColumn(last, last.width-1) {
+		t.Fatal("Expected the cursor to be placed in the last cell")
+	}
+
+	// 共享刻度时每个小图同样显示读数
+	tui.plotAreas = nil
+	output = tui.drawGridChart(tui.identifiers, 160, 40)
+	if count := strings.Count(output, cursorColor+cursorLineChar+" "); count != len(targets) {
+		t.Errorf("Expected a cursor readout in each of the %d cells, got %d", len(targets), count)
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if width := tview.TaggedStringWidth(line); width > 160 {
+			t.Errorf("Expected grid lines with readouts to fit in 160 columns, got %d", width)
+			break
+		}
+	}
+	if len(tui.plotAreas) != len(targets) || tui.plotAreas[0].top != 2 {
+		t.Errorf("Expected plot areas below each cell's title and readout, got %+v", tui.plotAreas)
+	}
+}
+
+// TestSparkline 测试表格中的迷你趋势图
+func TestSparkline(t *testing.T) {
+	tui := NewTUIForTest(newMockDataSource(), []string{"test.com"}, DefaultConfig(), pinger.DefaultConfig())
+	now := time.Now()
+
+	// 30次探测：前面的样本应被截掉，最近20次为从低到高的上升趋势，中间有一次丢包
+	for i := 0; i < 30; i++ {
+		latency := float64(i)
+		if i == 25 {
+			latency = math.NaN()
+		}
+		tui.updateStatsWithTime(core.PingResult{Identifier: "test.com", Latency: latency, SendTime: now.Add(time.Duration(i-30) * time.Second)})
+	}
+
+	line := sparkline(tui.statsData["test.com"], sparklineWidth)
+	if width := tview.TaggedStringWidth(line); width != sparklineWidth {
+		t.Errorf("Expected sparkline width %d, got %d", sparklineWidth, width)
+	}
+
+	plain := []rune(stripTags(line))
+	if plain[0] != sparklineBlocks[0] || plain[len(plain)-1] != sparklineBlocks[len(sparklineBlocks)-1] {
+		t.Errorf("Expected rising sparkline from lowest to highest block, got %q", string(plain))
+	}
+	if plain[15] != '×' || !strings.Contains(line, "[red]×") {
+		t.Errorf("Expected lost probe to be marked in red, got %q", line)
+	}
+
+	// 数据不足时只显示已有的探测
+	empty := newTargetStats("empty")
+	if line := sparkline(empty, sparklineWidth); tview.TaggedStringWidth(line) != 0 {
+		t.Errorf("Expected empty sparkline for no data, got %q", line)
+	}
+}
+
+// TestEventLog 测试事件检测、事件日志面板和事件日志文件
+func TestEventLog(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "events.log")
+	config := NewConfigWithOptions(WithEventLogSize(20), WithEventLogFile(logFile))
+	tui := NewTUIForTest(newMockDataSource(), []string{"test.com"}, config, pinger.DefaultConfig())
+	if err := tui.openEventLog(); err != nil {
+		t.Fatalf("Failed to open event log: %v", err)
+	}
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	send := func(i int, lost bool) {
+		latency := 10.0
+		if lost {
+			latency = math.NaN()
+		}
+		tui.handleDataUpdate(core.PingResult{Identifier: "test.com", Latency: latency, SendTime: start.Add(time.Duration(i) * time.Second)})
+	}
+
+	// 0-4正常，5-9中断，10-19正常，20-29间歇丢包（每3次丢1次），30-49正常
+	for i := 0; i < 50; i++ {
+		lost := (i >= 5 && i < 10) || (i >= 20 && i < 30 && i%3 == 0)
+		send(i, lost)
+	}
+	// 迟到回复不参与检测
+	tui.handleDataUpdate(core.PingResult{Identifier: "test.com", Latency: 5, SendTime: start.Add(5 * time.Second), Kind: core.ReplyLate})
+
+	tui.handleSourceEvent(core.SourceEvent{Time: start, Identifier: "test.com", Address: "192.0.2.1"})
+	tui.handleSourceEvent(core.SourceEvent{Time: start, Identifier: "test.com", Address: "192.0.2.2"})
+	tui.handleSourceEvent(core.SourceEvent{Time: start, Err: fmt.Errorf("socket error")})
+
+	expected := []eventKind{eventDown, eventRecovered, eventLossBurstStart, eventLossBurstEnd, eventAddress, eventAddress, eventError}
+	if len(tui.events) != len(expected) {
+		t.Fatalf("Expected %d events, got %d: %+v", len(expected), len(tui.events), tui.events)
+	}
+	for i, kind := range expected {
+		if tui.events[i].Kind != kind {
+			t.Errorf("Event %d: expected kind %d, got %+v", i, kind, tui.events[i])
+		}
+	}
+	if !tui.events[0].Time.Equal(start.Add(7*time.Second)) || !strings.Contains(tui.events[1].Detail, "5s") {
+		t.Errorf("Unexpected outage events: %+v %+v", tui.events[0], tui.events[1])
+	}
+	if !strings.Contains(tui.events[5].Detail, "192.0.2.1 → 192.0.2.2") {
+		t.Errorf("Expected address change detail, got %q", tui.events[5].Detail)
+	}
+
+	// 地址事件不应改变健康状态
+	if health := tui.statsData["test.com"].Health; health != core.HealthUnknown {
+		t.Errorf("Address events should not change health, got %v", health)
+	}
+
+	// 面板显示最新的事件，向前滚动后显示较早的事件
+	panel := tui.drawEventLog(3)
+	if lines := strings.Split(panel, "\n"); len(lines) != 3 || !strings.Contains(lines[2], "socket error") {
+		t.Errorf("Expected newest events at the bottom, got %q", panel)
+	}
+	tui.scrollEventLog(100)
+	if panel := tui.drawEventLog(3); !strings.Contains(panel, "目标中断") || tui.eventScroll != len(expected)-2 {
+		t.Errorf("Expected scroll to clamp at the oldest page, got scroll %d: %q", tui.eventScroll, panel)
+	}
+	tui.scrollEventLog(-100)
+	if tui.eventScroll != 0 {
+		t.Errorf("Expected scroll to return to latest, got %d", tui.eventScroll)
+	}
+
+	tui.closeEventLog()
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read event log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != len(expected) || !strings.HasPrefix(lines[0], "2024-01-01 12:00:07.000 test.com 目标中断") {
+		t.Errorf("Unexpected event log file content: %q", string(data))
+	}
+
+	// 超过保留条数时丢弃最早的事件
+	for i := 0; i < 30; i++ {
+		tui.recordEvents([]logEvent{{Time: start, Kind: eventError, Detail: "error"}})
+	}
+	if len(tui.events) != config.EventLogSize {
+		t.Errorf("Expected %d retained events, got %d", config.EventLogSize, len(tui.events))
+	}
+}
+
+// TestKeyBindingsAndCommands 测试按键分发、帮助文本和命令提示符
+func TestKeyBindingsAndCommands(t *testing.T) {
+	mock := &mockTargetSource{mockDataSource: newMockDataSource()}
+	pingerConfig := pinger.DefaultConfig()
+	tui := NewTUIForTest(mock, []string{"a.com", "b.com"}, DefaultConfig(), pingerConfig)
+	now := time.Now()
+	for _, target := range []string{"a.com", "b.com"} {
+		tui.updateStatsWithTime(core.PingResult{Identifier: target, Latency: 10, SendTime: now})
+	}
+
+	// 按键通过绑定表分发
+	if !tui.dispatchKey(tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone)) || !tui.logScale {
+		t.Error("Expected 'y' to toggle log scale")
+	}
+	if !tui.dispatchKey(tcell.NewEventKey(tcell.KeyRune, '-', tcell.ModNone)) || tui.zoomIndex != 1 {
+		t.Errorf("Expected '-' to zoom out, got zoom index %d", tui.zoomIndex)
+	}
+	if !tui.dispatchKey(tcell.NewEventKey(tcell.KeyRune, '=', tcell.ModNone)) || tui.zoomIndex != 0 {
+		t.Errorf("Expected '=' to zoom in, got zoom index %d", tui.zoomIndex)
+	}
+	if tui.dispatchKey(tcell.NewEventKey(tcell.KeyRune, 'z', tcell.ModNone)) {
+		t.Error("Unbound key should not be handled")
+	}
+
+	// 帮助文本包含所有按键和命令
+	help := tui.helpText()
+	for _, binding := range tui.bindings {
+		if !strings.Contains(help, binding.label) || !strings.Contains(help, binding.desc) {
+			t.Errorf("Help should list binding %q", binding.label)
+		}
+	}
+	for _, cmd := range tui.commands {
+		if !strings.Contains(help, ":"+cmd.name) {
+			t.Errorf("Help should list command %q", cmd.name)
+		}
+	}
+
+	// 按键对应的命令
+	if _, err := tui.executeCommand("log"); err != nil || tui.logScale {
+		t.Errorf("Expected :log to toggle log scale back, err=%v", err)
+	}
+	if _, err := tui.executeCommand("log extra"); err == nil {
+		t.Error("Expected error for arguments to a key command")
+	}
+	if _, err := tui.executeCommand("nosuch"); err == nil {
+		t.Error("Expected error for unknown command")
+	}
+
+	// 调整探测间隔
+	if _, err := tui.executeCommand("interval 1s"); err != nil {
+		t.Fatalf("Failed to set interval: %v", err)
+	}
+	if _, err := tui.executeCommand("interval 500ms b.com"); err != nil {
+		t.Fatalf("Failed to set target interval: %v", err)
+	}
+	if tui.targetInterval("a.com") != time.Second || tui.targetInterval("b.com") != 500*time.Millisecond {
+		t.Errorf("Expected TUI intervals to follow the data source, got %v %v", tui.targetInterval("a.com"), tui.targetInterval("b.com"))
+	}
+	if pingerConfig.Interval != pinger.DefaultConfig().Interval || len(pingerConfig.Overrides) != 0 {
+		t.Errorf("Runtime interval changes should not modify the caller's config, got %v %v", pingerConfig.Interval, pingerConfig.Overrides)
+	}
+	if len(mock.intervals) != 2 || mock.intervals[0] != "=1s" || mock.intervals[1] != "b.com=500ms" {
+		t.Errorf("Unexpected intervals sent to data source: %v", mock.intervals)
+	}
+	if _, err := tui.executeCommand("interval fast"); err == nil {
+		t.Error("Expected error for invalid duration")
+	}
+	if _, err := tui.executeCommand("interval 1ms"); err == nil || tui.targetInterval("a.com") != time.Second {
+		t.Error("Rejected interval should not change the TUI interval")
+	}
+
+	// 移除目标
+	// 移除在后台进行
+	if _, err := tui.executeCommand("rm b.com"); err != nil {
+		t.Errorf("Expected :rm to remove target, err=%v", err)
+	}
+	for i := 0; i < 100 && mock.removedCount() != 1; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if mock.removedCount() != 1 {
+		t.Errorf("Expected :rm to remove target, removed=%d", mock.removedCount())
+	}
+
+	// 不支持调整间隔的数据源
+	plain := NewTUIForTest(newMockDataSource(), []string{"a.com"}, DefaultConfig(), pinger.DefaultConfig())
+	if _, err := plain.executeCommand("interval 1s"); err == nil {
+		t.Error("Expected error when data source does not support interval changes")
+	}
+}
+
+// TestExportJSON 测试导出统计数据、历史和事件
+func TestExportJSON(t *testing.T) {
+	tui := NewTUIForTest(newMockDataSource(), []string{"a.com", "b.com"}, DefaultConfig(), pinger.DefaultConfig())
+	now := time.Now()
+	tui.updateStatsWithTime(core.PingResult{Identifier: "a.com", Latency: 10, SendTime: now.Add(-2 * time.Second)})
+	tui.updateStatsWithTime(core.PingResult{Identifier: "a.com", Latency: math.NaN(), SendTime: now.Add(-time.Second)})
+	tui.updateStatsWithTime(core.PingResult{Identifier: "b.com", Latency: math.NaN(), SendTime: now})
+	tui.recordEvents([]logEvent{{Time: now, Identifier: "a.com", Kind: eventDown, Detail: "目标中断"}})
+
+	path := filepath.Join(t.TempDir(), "export.json")
+	if _, err := tui.executeCommand("export " + path); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	var data exportData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		t.Fatalf("Export is not valid JSON: %v", err)
+	}
+
+	if len(data.Targets) != 2 || data.Targets[0].Identifier != "a.com" {
+		t.Fatalf("Expected targets in display order, got %+v", data.Targets)
+	}
+	a := data.Targets[0]
+	if a.Sent != 2 || a.Received != 1 || a.LossPercent != 50 || a.AvgMs == nil || *a.AvgMs != 10 {
+		t.Errorf("Unexpected stats for a.com: %+v", a)
+	}
+	for _, point := range a.History {
+		if point.Status == "interpolated" {
+			t.Error("Interpolated points should not be exported")
+		}
+	}
+	if last := a.History[len(a.History)-1]; last.Status != "timeout" || last.LatencyMs != nil {
+		t.Errorf("Expected timeout with null latency, got %+v", last)
+	}
+	if b := data.Targets[1]; b.AvgMs != nil || b.MinMs != nil || b.MaxMs != nil {
+		t.Errorf("Expected null latencies without samples, got %+v", b)
+	}
+	if len(data.Events) != 1 || data.Events[0].Target != "a.com" {
+		t.Errorf("Expected exported event, got %+v", data.Events)
+	}
+}
+
+// TestChartCursor 测试鼠标选择目标和折线图十字光标
+func TestChartCursor(t *testing.T) {
+	tui := NewTUIForTest(newMockDataSource(), []string{"a.com", "b.com"}, DefaultConfig(), pinger.DefaultConfig())
+	now := time.Now()
+	tui.startTime = now.Add(-time.Minute)
+	for i := 0; i < 20; i++ {
+		sendTime := now.Add(time.Duration(i-20) * time.Second)
+		latency := float64(10 + i)
+		if i == 10 {
+			latency = math.NaN()
+		}
+		tui.updateStatsWithTime(core.PingResult{Identifier: "a.com", Latency: latency, SendTime: sendTime})
+		tui.updateStatsWithTime(core.PingResult{Identifier: "b.com", Latency: 5, SendTime: sendTime})
+	}
+	tui.updateIdentifiersForTest()
+
+	// 未显示光标时没有读数行，并记录绘图区
+	windowStart, windowEnd := now.Add(-30*time.Second), now
+	points := map[string][]core.DataPoint{
+		"a.com": tui.statsData["a.com"].History,
+		"b.com": tui.statsData["b.com"].History,
+	}
+	colors := map[string]string{"a.com": "[green]", "b.com": "[yellow]"}
+	chart := tui.drawChartWithTimestamps(points, colors, 80, 20)
+	if strings.Contains(chart, cursorLineChar) {
+		t.Error("Chart should not show a cursor by default")
+	}
+	if len(tui.plotAreas) != 1 {
+		t.Fatalf("Expected one plot area, got %d", len(tui.plotAreas))
+	}
+	area := tui.plotAreas[0]
+	if area.width <= 0 || area.left+area.width != 80 || area.height != 18 {
+		t.Fatalf("Unexpected plot area: %+v", area)
+	}
+
+	// 把光标放到超时探测（10秒前）所在的列
+	column := int(float64(20*time.Second) / float64(30*time.Second) * float64(area.width))
+	if !tui.setCursorColumn(area, column) {
+		t.Fatal("Expected column inside plot area to set the cursor")
+	}
+	if tui.setCursorColumn(area, area.width) {
+		t.Error("Column outside plot area should not set the cursor")
+	}
+	cursorAt, ok := tui.cursorTime(windowStart, windowEnd)
+	if !ok || cursorAt.Sub(now.Add(-10*time.Second)).Abs() > 30*time.Second/time.Duration(area.width) {
+		t.Fatalf("Expected cursor near 10s ago, got %v", now.Sub(cursorAt))
+	}
+
+	// 读数取各目标最接近光标时间的数据点
+	tui.cursorOffset = 10 * time.Second
+	chart = tui.drawChartWithTimestamps(points, colors, 80, 20)
+	lines := strings.Split(chart, "\n")
+	if len(lines) != 20 {
+		t.Errorf("Expected readout line to share the chart height, got %d lines", len(lines))
+	}
+	readout := stripTags(lines[0])
+	if !strings.Contains(readout, "a.com t/o") || !strings.Contains(readout, "b.com 5.0ms") {
+		t.Errorf("Unexpected cursor readout: %q", readout)
+	}
+	if !strings.Contains(chart, cursorColor+cursorLineChar) {
+		t.Error("Expected cursor line in chart body")
+	}
+
+	// 宽度不够时读数只保留各目标的延迟和状态，并截断到给定宽度
+	compact := tui.cursorReadout(points, colors, windowStart, windowEnd, area.width, 24)
+	if width := tview.TaggedStringWidth(compact); width > 24 || !strings.Contains(stripTags(compact), "● t/o") {
+		t.Errorf("Unexpected compact readout %q (width %d)", compact, width)
+	}
+
+	// 光标随实时窗口滚动停留在同一列
+	if tui.cursorColumn(windowStart.Add(time.Second), windowEnd.Add(time.Second), area.width) != tui.cursorColumn(windowStart, windowEnd, area.width) {
+		t.Error("Cursor column should not move when the window scrolls")
+	}
+
+	tui.hideCursor()
+	if tui.cursorColumn(windowStart, windowEnd, area.width) != -1 {
+		t.Error("Expected no cursor column after hiding")
+	}
+
+	// 点击表格行选择目标，点击表头回到全选
+	for i := 0; i < 3; i++ {
+		row := tview.NewFlex()
+		row.SetRect(0, i, 80, 1)
+		tui.rowFlexes = append(tui.rowFlexes, row)
+	}
+	if !tui.handleMouse(5, 2, tview.MouseLeftClick) || tui.selectedRow != 1 {
+		t.Errorf("Expected click on second data row to select it, got %d", tui.selectedRow)
+	}
+	if !tui.handleMouse(5, 0, tview.MouseLeftClick) || tui.selectedRow != -1 {
+		t.Errorf("Expected click on header to select all, got %d", tui.selectedRow)
+	}
+	if tui.handleMouse(5, 10, tview.MouseLeftClick) {
+		t.Error("Click outside rows and chart should not be handled")
+	}
+}
+
+// TestCursorMode 测试键盘光标模式和光标读数中的数据点状态
+func TestCursorMode(t *testing.T) {
+	tui := NewTUIForTest(newMockDataSource(), []string{"a.com"}, DefaultConfig(), pinger.DefaultConfig())
+	now := time.Now()
+	tui.startTime = now.Add(-time.Minute)
+	window := tui.windowDuration()
+
+	if !tui.dispatchKey(tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone)) || !tui.cursorMode || !tui.cursorShown {
+		t.Fatal("Expected 'c' to enter cursor mode")
+	}
+	if tui.cursorOffset != window/2 {
+		t.Errorf("Expected cursor to start in the middle of the window, got offset %v", tui.cursorOffset)
+	}
+
+	// 光标模式下左右方向键移动光标而不是平移视图
+	tui.plotAreas = []plotArea{{width: 100}}
+	step := window / 100
+	tui.dispatchKey(tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModNone))
+	if tui.paused || tui.cursorOffset != window/2+step {
+		t.Errorf("Expected left key to move cursor one column, paused=%v offset=%v", tui.paused, tui.cursorOffset)
+	}
+	tui.moveCursor(1000)
+	if tui.cursorOffset != step/2 {
+		t.Errorf("Expected cursor to stop at the newest column, got offset %v", tui.cursorOffset)
+	}
+	tui.moveCursor(-1000)
+	if tui.cursorOffset != window-step/2 {
+		t.Errorf("Expected cursor to stop at the oldest column, got offset %v", tui.cursorOffset)
+	}
+
+	// 光标模式下鼠标离开图表不隐藏光标
+	tui.hideCursor()
+	if !tui.cursorShown {
+		t.Error("Cursor should stay visible in cursor mode")
+	}
+
+	// 读数包含各状态
+	points := []core.DataPoint{
+		{Timestamp: now.Add(-4 * time.Second), Value: 10, Status: core.PointSuccess},
+		{Timestamp: now.Add(-3 * time.Second), Value: math.NaN(), Status: core.PointTimeout},
+		{Timestamp: now.Add(-2 * time.Second), Value: 15, Status: core.PointInterpolated},
+		{Timestamp: now.Add(-time.Second), Value: 3200, Status: core.PointLate},
+	}
+	tui.identifiers = []string{"a.com"}
+	for i, expected := range []string{"10.0ms 成功", "t/o 超时", "15.0ms 插值", "3.20s 迟到"} {
+		tui.cursorOffset = time.Duration(4-i) * time.Second
+		readout := stripTags(tui.cursorReadout(map[string][]core.DataPoint{"a.com": points}, map[string]string{}, now.Add(-window), now, 100, 200))
+		if !strings.Contains(readout, "a.com "+expected) {
+			t.Errorf("Expected readout to contain %q, got %q", expected, readout)
+		}
+	}
+
+	if !tui.dispatchKey(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)) || tui.cursorMode || tui.cursorShown {
+		t.Error("Expected Esc to leave cursor mode and hide the cursor")
+	}
+	tui.dispatchKey(tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModNone))
+	if !tui.paused {
+		t.Error("Left key should pan the view outside cursor mode")
+	}
+}
+
+// stripTags 去除文本中的颜色标签
+func stripTags(text string) string {
+	var builder strings.Builder
+	inTag := false
+	for _, r := range text {
+		switch {
+		case r == '[':
+			inTag = true
+		case r == ']' && inTag:
+			inTag = false
+		case !inTag:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+// TestHiddenTargets 测试在全选图表中隐藏目标
+func TestHiddenTargets(t *testing.T) {
+	targets := []string{"a.com", "b.com"}
+	tui := NewTUIForTest(newMockDataSource(), targets, DefaultConfig(), pinger.DefaultConfig())
+
+	now := time.Now()
+	tui.startTime = now.Add(-time.Minute)
+	for i := 0; i < 20; i++ {
+		sendTime := now.Add(time.Duration(i-20) * time.Second)
+		tui.updateStatsWithTime(core.PingResult{Identifier: "a.com", Latency: 10, SendTime: sendTime})
+		tui.updateStatsWithTime(core.PingResult{Identifier: "b.com", Latency: 900, SendTime: sendTime})
+	}
+	tui.updateIdentifiersForTest()
+
+	if chart := tui.drawMultiTargetChart(60, 12); !strings.Contains(chart, "990.0ms") {
+		t.Fatal("Expected the slow target to dominate the Y-scale before hiding")
+	}
+
+	// 全选时空格提示先选择目标
+	space := tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone)
+	tui.dispatchKey(space)
+	if len(tui.hiddenTargets) != 0 || !strings.Contains(tui.message, "选择") {
+		t.Errorf("Expected space in all-targets mode to only show a hint, got %q", tui.message)
+	}
+
+	// 选中b.com后按空格隐藏
+	tui.selectedRow = 1
+	tui.dispatchKey(space)
+	if !tui.hiddenTargets["b.com"] {
+		t.Fatal("Expected space to hide the selected target")
+	}
+	tui.selectedRow = -1
+	chart := tui.drawMultiTargetChart(60, 12)
+	if strings.Contains(chart, "990.0ms") || strings.Contains(chart, "[yellow]⣀") {
+		t.Error("Hidden target should be excluded from drawing and auto-scaling")
+	}
+	if got := tui.chartTargets(); len(got) != 1 || got[0] != "a.com" {
+		t.Errorf("Expected only visible targets in all-targets mode, got %v", got)
+	}
+
+	// 隐藏的目标仍在表格中并继续统计，单选时照常绘制
+	if len(tui.identifiers) != 2 {
+		t.Errorf("Hidden target should stay listed, got %v", tui.identifiers)
+	}
+	tui.updateStatsWithTime(core.PingResult{Identifier: "b.com", Latency: 900, SendTime: now})
+	if tui.statsData["b.com"].PacketsSent != 21 {
+		t.Errorf("Hidden target should keep being counted, got %d sent", tui.statsData["b.com"].PacketsSent)
+	}
+	tui.selectedRow = 1
+	if got := tui.chartTargets(); len(got) != 1 || got[0] != "b.com" {
+		t.Errorf("Expected the selected hidden target to be drawn, got %v", got)
+	}
+
+	// 再次按空格重新显示
+	tui.dispatchKey(space)
+	if tui.hiddenTargets["b.com"] {
+		t.Error("Expected space to show the hidden target again")
+	}
+
+	// 消息中的目标名称经过转义，不会被当作颜色标签
+	tagged := NewTUIForTest(newMockDataSource(), []string{"x[red]"}, DefaultConfig(), pinger.DefaultConfig())
+	tagged.updateStatsWithTime(core.PingResult{Identifier: "x[red]", Latency: 10, SendTime: now})
+	tagged.updateIdentifiersForTest()
+	tagged.selectedRow = 0
+	tagged.toggleTargetHidden()
+	if !strings.Contains(tagged.message, "x[red[]") {
+		t.Errorf("Expected the target name to be escaped in the message, got %q", tagged.message)
+	}
+}
+
+// TestTableColumnsAndSorting 测试可配置的表格列和按列排序
+func TestTableColumnsAndSorting(t *testing.T) {
+	// 列名校验
+	for _, columns := range [][]string{{"status", "bogus"}, {"loss", "loss"}} {
+		if err := NewConfigWithOptions(WithColumns(columns...)).Validate(); err == nil {
+			t.Errorf("Expected columns %v to be rejected", columns)
+		}
+	}
+	if err := NewConfigWithOptions(WithSort("bogus", true)).Validate(); err == nil {
+		t.Error("Expected unknown sort column to be rejected")
+	}
+
+	config := NewConfigWithOptions(WithColumns("loss", "last", "stddev", "p50", "p99"))
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+	targets := []string{"a.com", "b.com", "c.com"}
+	tui := NewTUIForTest(newMockDataSource(), targets, config, pinger.DefaultConfig())
+	if columns := tui.tableColumns(); len(columns) != 5 || columns[0].header != "丢包率" || columns[4].header != "P99" {
+		t.Errorf("Unexpected table columns %v", columns)
+	}
+
+	now := time.Now()
+	tui.startTime = now.Add(-time.Minute)
+	latencies := map[string][]float64{
+		"a.com": {10, 20, 30, math.NaN()},
+		"b.com": {50, math.NaN(), math.NaN(), math.NaN()},
+		"c.com": {5, 5, 5, 7},
+	}
+	for i := 0; i < 4; i++ {
+		sendTime := now.Add(time.Duration(i-4) * time.Second)
+		for _, target := range targets {
+			tui.updateStatsWithTime(core.PingResult{Identifier: target, Latency: latencies[target][i], SendTime: sendTime})
+		}
+	}
+	tui.updateIdentifiersForTest()
+
+	// 新增统计列
+	summary := tui.statsData["a.com"].Summary
+	if summary["最近延迟"] != formatLatency(30) || summary["标准差"] != formatLatency(10) {
+		t.Errorf("Unexpected last/stddev summary: %q %q", summary["最近延迟"], summary["标准差"])
+	}
+	if summary["P50"] == "N/A" || summary["P99"] == "N/A" {
+		t.Error("Expected percentile summaries")
+	}
+	if tui.statsData["b.com"].Summary["标准差"] != "N/A" {
+		t.Error("Expected N/A stddev with a single sample")
+	}
+
+	colors := make(map[string]string)
+	for _, target := range targets {
+		colors[target] = tui.getTargetColor(target)
+	}
+
+	// 选中c.com后按丢包率降序排列，选中的目标跟随移动
+	tui.selectedRow = 2
+	if err := tui.setSort("loss", true); err != nil {
+		t.Fatal(err)
+	}
+	tui.updateIdentifiersForTest()
+	expected := []string{"b.com", "a.com", "c.com"}
+	for i, identifier := range expected {
+		if tui.identifiers[i] != identifier {
+			t.Fatalf("Expected order %v, got %v", expected, tui.identifiers)
+		}
+	}
+	if tui.selectedIdentifier() != "c.com" {
+		t.Errorf("Expected selection to follow c.com, got %q", tui.selectedIdentifier())
+	}
+
+	// 按S反转方向
+	tui.dispatchKey(tcell.NewEventKey(tcell.KeyRune, 'S', tcell.ModNone))
+	if tui.identifiers[0] != "c.com" || tui.selectedRow != 0 {
+		t.Errorf("Expected ascending loss order, got %v", tui.identifiers)
+	}
+
+	// 按s切换到下一个显示的列，并默认降序
+	tui.dispatchKey(tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone))
+	if tui.sortColumn != "last" || !tui.sortDescending || tui.identifiers[0] != "b.com" {
+		t.Errorf("Expected descending sort by last RTT, got %s %v", tui.sortColumn, tui.identifiers)
+	}
+
+	// 排序不影响颜色分配
+	for _, target := range targets {
+		if tui.getTargetColor(target) != colors[target] {
+			t.Errorf("Expected color of %s to stay stable after sorting", target)
+		}
+	}
+
+	// 排序不影响图表中折线的绘制顺序
+	points := make(map[string][]core.DataPoint)
+	for _, target := range targets {
+		points[target] = tui.statsData[target].History
+	}
+	if order := tui.drawOrder(points); strings.Join(order, ",") != strings.Join(targets, ",") {
+		t.Errorf("Expected chart draw order %v regardless of table order, got %v", targets, order)
+	}
+	tui.paused, tui.viewEnd = true, time.Now() // 固定时间窗口，两次绘制可直接比较
+	sortedChart := tui.drawMultiTargetChart(60, 12)
+
+	// 命令：不带参数恢复原始顺序，带方向参数
+	if _, err := tui.executeCommand("sort avg asc"); err != nil || tui.sortColumn != "avg" || tui.sortDescending {
+		t.Errorf("Expected ascending sort by avg, got %s desc=%v err=%v", tui.sortColumn, tui.sortDescending, err)
+	}
+	if tui.identifiers[0] != "c.com" {
+		t.Errorf("Expected c.com to have the lowest avg, got %v", tui.identifiers)
+	}
+	if _, err := tui.executeCommand("sort avg up"); err == nil {
+		t.Error("Expected invalid sort direction to be rejected")
+	}
+	if _, err := tui.executeCommand("sort"); err != nil || tui.sortColumn != "" {
+		t.Errorf("Expected sort without arguments to restore original order, err=%v", err)
+	}
+	for i, identifier := range targets {
+		if tui.identifiers[i] != identifier {
+			t.Fatalf("Expected original order %v, got %v", targets, tui.identifiers)
+		}
+	}
+	if chart := tui.drawMultiTargetChart(60, 12); chart != sortedChart {
+		t.Error("Expected the chart to be the same in any table order")
+	}
+}
+
+// TestThemes 测试颜色主题、主题文件和单色主题的线型
+func TestThemes(t *testing.T) {
+	if err := NewConfigWithOptions(WithTheme("pink")).Validate(); err == nil {
+		t.Error("Expected unknown theme to be rejected")
+	}
+	for _, name := range ThemeNames() {
+		if err := NewConfigWithOptions(WithTheme(name)).Validate(); err != nil {
+			t.Errorf("Expected builtin theme %s to be valid, got %v", name, err)
+		}
+	}
+
+	targets := []string{"a.com", "b.com"}
+
+	// 默认深色主题保持原有配色
+	dark := NewTUIForTest(newMockDataSource(), targets, DefaultConfig(), pinger.DefaultConfig())
+	if text := "[red]t/o[white]"; dark.themed(text) != text {
+		t.Errorf("Dark theme should not rewrite colors, got %q", dark.themed(text))
+	}
+	if dark.getTargetColor("a.com") != "[green]" || dark.linePattern("b.com") != "" {
+		t.Error("Unexpected dark theme target style")
+	}
+
+	// 色盲友好主题替换状态颜色和目标颜色，表格单元格使用相同的颜色
+	colorblind := NewTUIForTest(newMockDataSource(), targets, NewConfigWithOptions(WithTheme("colorblind"), WithLossThresholds(5, 20)), pinger.DefaultConfig())
+	if got := colorblind.themed("[green]正常[white] [red]t/o[white] [foo[]"); got != "[#56b4e9]正常[white] [#d55e00]t/o[white] [foo[]" {
+		t.Errorf("Unexpected themed text %q", got)
+	}
+	if colorblind.getTargetColor("a.com") != "[#e69f00]" {
+		t.Errorf("Unexpected colorblind target color %q", colorblind.getTargetColor("a.com"))
+	}
+	stats := newTargetStats("a.com")
+	for i := 0; i < 10; i++ {
+		stats.History = append(stats.History, core.DataPoint{Timestamp: time.Now(), Value: math.NaN(), Status: core.PointTimeout})
+	}
+	if got := colorblind.cellColor(stats, "丢包率"); got != tcell.GetColor("#d55e00") {
+		t.Errorf("Expected critical cell to use the theme color, got %v", got)
+	}
+
+	// 单色主题以线型区分目标
+	mono := NewTUIForTest(newMockDataSource(), targets, NewConfigWithOptions(WithTheme("mono")), pinger.DefaultConfig())
+	if mono.linePattern("a.com") != "" || mono.linePattern("b.com") == "" {
+		t.Error("Expected mono theme to give the second target a dashed line")
+	}
+	canvas := make([][]canvasCell, 8)
+	for i := range canvas {
+		canvas[i] = make([]canvasCell, 1)
+	}
+	mono.drawCanvasLine(canvas, 0, 0, 15, 0, 4, 16, "[-]", "1000")
+	dots := 0
+	for _, column := range canvas {
+		if column[0].mask != 0 {
+			dots++
+		}
+	}
+	if dots != 4 {
+		t.Errorf("Expected a dotted line to mark every other cell, got %d cells", dots)
+	}
+
+	// 主题只作用于本界面的组件，不修改tview的全局样式
+	globalStyles := tview.Styles
+	light := NewTUI(newMockDataSource(), targets, NewConfigWithOptions(WithTheme("light")), pinger.DefaultConfig())
+	if tview.Styles != globalStyles {
+		t.Error("Creating a themed TUI should not change tview's global styles")
+	}
+	if light.chart.GetBackgroundColor() != tcell.ColorDefault || light.chart.GetBorderColor() != tcell.GetColor("#6c6c6c") {
+		t.Errorf("Expected chart to use the light theme, got background %v border %v",
+			light.chart.GetBackgroundColor(), light.chart.GetBorderColor())
+	}
+	if dark.newTextView().GetBackgroundColor() != tview.Styles.PrimitiveBackgroundColor {
+		t.Error("Dark theme should keep tview's default background")
+	}
+
+	// 主题文件在基础主题上覆盖颜色
+	dir := t.TempDir()
+	path := filepath.Join(dir, "theme.json")
+	if err := os.WriteFile(path, []byte(`{"base": "light", "critical": "#ff00ff", "palette": ["red", "blue"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	theme, err := LoadThemeFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load theme file: %v", err)
+	}
+	if theme.Critical != "#ff00ff" || theme.Text != "black" || len(theme.Palette) != 2 {
+		t.Errorf("Unexpected theme loaded from file: %+v", theme)
+	}
+	if err := NewConfigWithOptions(WithCustomTheme(theme)).Validate(); err != nil {
+		t.Errorf("Expected custom theme to be valid, got %v", err)
+	}
+	for _, content := range []string{`{"base": "sepia"}`, `{"warning": "notacolor"}`, `{"palette": []}`, `{`} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadThemeFile(path); err == nil {
+			t.Errorf("Expected theme file %s to be rejected", content)
+		}
+	}
+}
+
+// TestRenderers 测试盲文、方块和ASCII字符集的折线图绘制及自动选择
+func TestRenderers(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(key string) string { return values[key] }
+	}
+	cases := []struct {
+		values   map[string]string
+		goos     string
+		expected string
+	}{
+		{map[string]string{"LANG": "en_US.UTF-8", "TERM": "xterm-256color"}, "linux", "braille"},
+		{map[string]string{"LANG": "C"}, "linux", "ascii"},
+		{map[string]string{"LC_ALL": "zh_CN.utf8", "LANG": "C"}, "linux", "braille"},
+		{map[string]string{"LANG": "en_US.UTF-8", "TERM": "linux"}, "linux", "block"},
+		{map[string]string{}, "windows", "block"},
+		{map[string]string{"WT_SESSION": "1"}, "windows", "braille"},
+	}
+	for _, c := range cases {
+		if got := detectRenderer(env(c.values), c.goos); got != c.expected {
+			t.Errorf("detectRenderer(%v, %s) = %s, expected %s", c.values, c.goos, got, c.expected)
+		}
+	}
+	if err := NewConfigWithOptions(WithRenderer("sixel")).Validate(); err == nil {
+		t.Error("Expected unknown renderer to be rejected")
+	}
+
+	tui := NewTUIForTest(newMockDataSource(), []string{"a.com"}, NewConfigWithOptions(WithRenderer("braille")), pinger.DefaultConfig())
+	now := time.Now()
+	tui.startTime = now.Add(-time.Minute)
+	for i := 0; i < 10; i++ {
+		tui.updateStatsWithTime(core.PingResult{Identifier: "a.com", Latency: float64(10 + i*10), SendTime: now.Add(time.Duration(i-10) * time.Second)})
+	}
+	tui.updateIdentifiersForTest()
+
+	// 各字符集共用时间和延迟的坐标映射，线条从同一列开始
+	firstColumn := func(chart string) int {
+		for _, line := range strings.Split(chart, "\n") {
+			// ASCII字符集中Y轴竖线被替换为|
+			_, plot, found := strings.Cut(strings.ReplaceAll(stripTags(line), "│", "|"), "|")
+			if !found {
+				continue
+			}
+			if index := strings.IndexFunc(plot, func(r rune) bool { return r != ' ' && r != '⠀' }); index >= 0 {
+				return len([]rune(plot[:index]))
+			}
+		}
+		return -1
+	}
+	charts := make(map[string]string)
+	for _, name := range []string{"braille", "block", "ascii"} {
+		if _, err := tui.executeCommand("renderer " + name); err != nil {
+			t.Fatalf("Failed to switch renderer to %s: %v", name, err)
+		}
+		charts[name] = tui.display(tui.drawMultiTargetChart(80, 12))
+	}
+	if !strings.ContainsAny(charts["braille"], "⠁⠂⠄⡀⠈⠐⠠⢀⣀⠉") {
+		t.Error("Expected braille chart to contain braille glyphs")
+	}
+	if !strings.ContainsAny(charts["block"], "▀▄█") || strings.ContainsAny(charts["block"], "⣀⠉") {
+		t.Error("Expected block chart to use half block glyphs only")
+	}
+	for _, r := range stripTags(charts["ascii"]) {
+		if r > 127 {
+			t.Errorf("Expected ASCII chart to only contain ASCII characters, found %q", r)
+			break
+		}
+	}
+	start := firstColumn(charts["braille"])
+	if start < 0 || firstColumn(charts["block"]) != start || firstColumn(charts["ascii"]) != start {
+		t.Errorf("Expected lines to start at the same column, got braille=%d block=%d ascii=%d",
+			start, firstColumn(charts["block"]), firstColumn(charts["ascii"]))
+	}
+
+	if _, err := tui.executeCommand("renderer sixel"); err == nil {
+		t.Error("Expected unknown renderer to be rejected by the command")
+	}
+}
+
+// TestTimeAxis 测试X轴时间刻度：间隔随窗口和宽度自适应，时钟时间对齐整数倍，相对时间以当前时刻为零点
+func TestTimeAxis(t *testing.T) {
+	tui := NewTUIForTest(newMockDataSource(), []string{"a.com"}, NewConfigWithOptions(), pinger.DefaultConfig())
+	now := time.Date(2026, 3, 1, 12, 0, 7, 0, time.Local)
+
+	// 时钟时间：1分钟窗口，刻度落在整数倍间隔上
+	ticks := tui.timeTicks(100, now.Add(-time.Minute), now, now)
+	if len(ticks) < 3 {
+		t.Fatalf("Expected at least 3 ticks in a 1m window, got %d", len(ticks))
+	}
+	step := tui.timeTickStep(100, time.Minute)
+	for i, tick := range ticks {
+		parsed, err := time.ParseInLocation("15:04:05", tick.label, time.Local)
+		if err != nil {
+			t.Fatalf("Expected HH:MM:SS label, got %q", tick.label)
+		}
+		if parsed.Second()%int(step/time.Second) != 0 {
+			t.Errorf("Expected tick %q to be aligned to %v", tick.label, step)
+		}
+		if i > 0 && tick.col-ticks[i-1].col < len(tick.label)+timeTickGap-1 {
+			t.Errorf("Expected ticks to be spaced for their labels, got columns %d and %d", ticks[i-1].col, tick.col)
+		}
+	}
+
+	// 更窄的图表使用更大的间隔
+	if narrow := tui.timeTickStep(40, time.Minute); narrow <= step {
+		t.Errorf("Expected a narrower chart to use a larger step than %v, got %v", step, narrow)
+	}
+
+	// 24小时窗口：省略秒，零点显示日期
+	ticks = tui.timeTicks(100, now.Add(-24*time.Hour), now, now)
+	foundDate := false
+	for _, tick := range ticks {
+		if tick.label == "03-01" {
+			foundDate = true
+		} else if _, err := time.Parse("15:04", tick.label); err != nil {
+			t.Errorf("Expected HH:MM label in a 24h window, got %q", tick.label)
+		}
+	}
+	if !foundDate {
+		t.Error("Expected the midnight tick to show the date")
+	}
+
+	// 相对时间：最右侧为"现在"，其余为负的偏移
+	tui.toggleRelativeTime()
+	ticks = tui.timeTicks(100, now.Add(-5*time.Minute), now, now)
+	if len(ticks) < 3 || ticks[len(ticks)-1].label != "现在" {
+		t.Fatalf("Expected relative ticks ending with 现在, got %v", ticks)
+	}
+	for _, tick := range ticks[:len(ticks)-1] {
+		if !strings.HasPrefix(tick.label, "-") {
+			t.Errorf("Expected negative relative label, got %q", tick.label)
+		}
+	}
+	if formatRelativeTime(-90*time.Second) != "-1m30s" || formatRelativeTime(-2*time.Hour) != "-2h" {
+		t.Error("Unexpected relative time format")
+	}
+
+	// 回看较早的窗口时没有"现在"，刻度仍以当前时刻为零点
+	ticks = tui.timeTicks(100, now.Add(-15*time.Minute), now.Add(-10*time.Minute), now)
+	if len(ticks) == 0 || ticks[len(ticks)-1].label != "-10m" {
+		t.Errorf("Expected the latest tick of a past window to be -10m, got %v", ticks)
+	}
+
+	// 绘制的标签行不超出图表宽度，X轴上标出刻度
+	tui.startTime = now.Add(-time.Hour)
+	lines := tui.drawTimeAxis(8, 30, now.Add(-time.Minute), now)
+	if !strings.Contains(lines[0], "┬") {
+		t.Error("Expected tick marks on the X axis")
+	}
+	if width := tview.TaggedStringWidth(lines[1]); width > 8+30+1 {
+		t.Errorf("Expected tick labels to fit in the chart width, got width %d", width)
+	}
+
+	if _, err := tui.executeCommand("time"); err != nil || tui.relativeTime {
+		t.Errorf("Expected :time to switch back to clock time, err=%v", err)
+	}
+	if header := tui.chartHeader(); !strings.Contains(header, tui.startTime.Format("2006-01-02 15:04:05")) {
+		t.Errorf("Expected header to show the session start time, got %q", header)
+	}
+}
+
+// mockDroppingSource 报告丢弃结果数的模拟数据源
+type mockDroppingSource struct {
+	*mockDataSource
+	dropped uint64
+}
+
+func (m *mockDroppingSource) DroppedResults() uint64 {
+	return m.dropped
+}
+
+func (m *mockDroppingSource) EngineInfo() (implementation, privilege string) {
+	return "Linux Raw Socket", "特权模式"
+}
+
+// TestStatusBar 测试底部状态栏：探测引擎、探测设置、运行时长、结果速率和丢弃的结果数
+func TestStatusBar(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, int(500*time.Millisecond), time.Local)
+
+	// 只统计最近rateWindow个完整的秒，当前这一秒不计入
+	var counter rateCounter
+	for second := rateWindow + 3; second >= 1; second-- {
+		counter.add(now.Add(-time.Duration(second) * time.Second))
+		counter.add(now.Add(-time.Duration(second) * time.Second))
+	}
+	for i := 9; i >= 0; i-- {
+		counter.add(now.Add(-time.Duration(i) * 10 * time.Millisecond))
+	}
+	if rate := counter.rate(now, now.Add(-time.Hour)); rate != 2 {
+		t.Errorf("Expected 2 probes per second, got %v", rate)
+	}
+	if rate := counter.rate(now, now.Add(-2*time.Second)); rate != 2 {
+		t.Errorf("Expected the rate to use the elapsed seconds right after start, got %v", rate)
+	}
+	if rate := counter.rate(now, now); rate != 0 {
+		t.Errorf("Expected no rate before a full second has elapsed, got %v", rate)
+	}
+
+	mock := &mockDroppingSource{mockDataSource: newMockDataSource()}
+	pingerConfig := pinger.DefaultConfig()
+	pingerConfig.Interval = 500 * time.Millisecond
+	pingerConfig.SetTargetOverride("gateway", 100*time.Millisecond, 0)
+	tui := NewTUIForTest(mock, []string{"a.com"}, DefaultConfig(), pingerConfig)
+	tui.startTime = now.Add(-90 * time.Second)
+
+	text := tui.statusBarText(now)
+	for _, want := range []string{"Linux Raw Socket · 特权模式", "间隔 500ms", "1个目标单独设置", "已运行 1m30s", "0.0 结果/秒", "丢弃 0"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected status bar to contain %q, got %q", want, text)
+		}
+	}
+
+	mock.dropped = 7
+	if text := tui.statusBarText(now); !strings.Contains(text, "[red]丢弃 7") {
+		t.Errorf("Expected dropped results to be highlighted, got %q", text)
+	}
+
+	// 不报告丢弃数和探测引擎的数据源不显示这两项
+	plain := NewTUIForTest(newMockDataSource(), []string{"a.com"}, DefaultConfig(), pinger.DefaultConfig())
+	if text := plain.statusBarText(now); strings.Contains(text, "丢弃") || strings.Contains(text, "Socket") {
+		t.Errorf("Expected no dropped counter or engine info for sources without them, got %q", text)
+	}
+}
+
+// TestResetAndMarkers 测试重置统计和时间轴标记
+func TestResetAndMarkers(t *testing.T) {
+	tui := NewTUIForTest(newMockDataSource(), []string{"a.com", "b.com"}, DefaultConfig(), pinger.DefaultConfig())
+	now := time.Now()
+	tui.startTime = now.Add(-time.Minute)
+	for i := 0; i < 10; i++ {
+		sendTime := now.Add(time.Duration(i-29) * time.Second)
+		tui.updateStatsWithTime(core.PingResult{Identifier: "a.com", Latency: 10, SendTime: sendTime})
+		tui.updateStatsWithTime(core.PingResult{Identifier: "b.com", Latency: math.NaN(), SendTime: sendTime})
+	}
+	tui.updateIdentifiersForTest()
+	historyLength := len(tui.statsData["a.com"].History)
+
+	// 选中目标时只重置该目标
+	tui.selectedRow = 0
+	tui.resetStats()
+	a, b := tui.statsData["a.com"], tui.statsData["b.com"]
+	if a.PacketsSent != 0 || a.WelfordCount != 0 || a.distribution.total != 0 || a.Summary["发送/接收"] != "0/0" {
+		t.Errorf("Expected a.com counters to be reset, got sent=%d summary=%v", a.PacketsSent, a.Summary["发送/接收"])
+	}
+	if len(a.History) != historyLength {
+		t.Error("Expected chart history to be kept after reset")
+	}
+	if b.PacketsSent != 10 {
+		t.Errorf("Expected b.com to keep its counters, got %d", b.PacketsSent)
+	}
+
+	// 全选时重置所有目标，每个目标记录一条事件
+	tui.selectedRow = -1
+	tui.resetStats()
+	if b.PacketsSent != 0 {
+		t.Errorf("Expected all targets to be reset, b.com sent=%d", b.PacketsSent)
+	}
+	resets := 0
+	for _, event := range tui.events {
+		if event.Kind == eventReset {
+			resets++
+		}
+	}
+	if resets != 3 {
+		t.Errorf("Expected 3 reset events, got %d", resets)
+	}
+
+	// 重置前发出、重置后才收到迟到回复的探测只更新图表，丢包率不会变为负数
+	resetTUI := NewTUIForTest(newMockDataSource(), []string{"b.com"}, DefaultConfig(), pinger.DefaultConfig())
+	lost := now.Add(-2 * time.Second)
+	resetTUI.updateStatsWithTime(core.PingResult{Identifier: "b.com", Latency: math.NaN(), SendTime: lost})
+	resetTUI.resetStats()
+	resetTUI.updateStatsWithTime(core.PingResult{Identifier: "b.com", Latency: 10, SendTime: time.Now()})
+	resetTUI.updateStatsWithTime(core.PingResult{Identifier: "b.com", Latency: 2500, SendTime: lost, Kind: core.ReplyLate})
+	resetTUI.updateStatsWithTime(core.PingResult{Identifier: "b.com", Latency: 2600, SendTime: lost, Kind: core.ReplyDuplicate})
+	stats := resetTUI.statsData["b.com"]
+	if rate := lossRate(stats); rate != 0 || stats.LateReplies != 0 || stats.Duplicates != 0 || stats.PacketsSent != 1 {
+		t.Errorf("Expected replies to pre-reset probes to be ignored, got loss=%v late=%d dup=%d sent=%d",
+			rate, stats.LateReplies, stats.Duplicates, stats.PacketsSent)
+	}
+	if stats.MaxLatency != 10 {
+		t.Errorf("Expected the late latency to stay out of the reset counters, got max %v", stats.MaxLatency)
+	}
+	lateShown := false
+	for _, point := range stats.History {
+		if point.Timestamp.Equal(lost) && point.Status == core.PointLate {
+			lateShown = true
+		}
+	}
+	if !lateShown {
+		t.Error("Expected the late reply to still be shown in the chart")
+	}
+
+	// 标记画成竖线，标签显示在第一行；方括号不会被当作颜色标签
+	tui.addMarker(now.Add(-10*time.Second), "变更[1]")
+	chart := tui.drawMultiTargetChart(80, 12)
+	lines := strings.Split(chart, "\n")
+	if !strings.Contains(stripTags(lines[0]), "变更(1)") {
+		t.Errorf("Expected marker label in the first row, got %q", stripTags(lines[0]))
+	}
+	if strings.Count(chart, markerLineChar) < 5 {
+		t.Error("Expected marker to be drawn as a vertical line")
+	}
+	tui.addMarker(now.Add(-2*time.Hour), "窗口外")
+	if strings.Contains(stripTags(tui.drawMultiTargetChart(80, 12)), "窗口外") {
+		t.Error("Markers outside the window should not be drawn")
+	}
+
+	if _, err := tui.executeCommand("mark"); err != nil || tui.markers[2].Label != "标记3" {
+		t.Errorf("Expected :mark without label to use a default label, err=%v markers=%v", err, tui.markers)
+	}
+
+	// 标记记入事件日志和导出文件
+	last := tui.events[len(tui.events)-1]
+	if last.Kind != eventMarker || last.target() != "标记" || last.Detail != "标记3" {
+		t.Errorf("Expected marker event, got %+v", last)
+	}
+	path := filepath.Join(t.TempDir(), "export.json")
+	if err := tui.exportJSON(path); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	var data exportData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		t.Fatalf("Export is not valid JSON: %v", err)
+	}
+	if len(data.Markers) != 3 || data.Markers[0].Label != "变更[1]" {
+		t.Errorf("Expected exported markers, got %+v", data.Markers)
+	}
+}
@@ -4,8 +4,10 @@ package tui
 import (
 	"fmt"
 	"math"
+	"sort"
 
 	"github.com/Kevin-Rudy/goping/pkg/core"
+	"github.com/rivo/tview"
 )
 
 // formatLatency 提供自适应的延迟格式化
@@ -28,35 +30,70 @@ func formatLatency(latency float64) string {
 
 // getTargetColor 根据目标标识符获取对应的颜色（统一颜色分配逻辑）
 func (t *TUI) getTargetColor(identifier string) string {
-	// 重新设计的颜色序列，使用更兼容、更鲜明的颜色
-	colorSequence := []string{
-		"[green]",       // 绿色 - 通常显示良好
-		"[yellow]",      // 黄色 - 通常显示良好
-		"[blue]",        // 蓝色 - 通常显示良好
-		"[red]",         // 红色 - 通常显示良好
-		"[lightgreen]",  // 浅绿色
-		"[lightblue]",   // 浅蓝色
-		"[lightyellow]", // 浅黄色
-		"[lightred]",    // 浅红色
-		"[white]",       // 白色 - 在深色背景下醒目
-		"[gray]",        // 灰色
-		"[darkgreen]",   // 深绿色
-		"[darkblue]",    // 深蓝色
-		"[darkyellow]",  // 深黄色
-		"[darkred]",     // 深红色
-	}
+	// 颜色序列由主题决定，见theme.go
+	palette := t.theme.Palette
 
-	// 基于预定义的targets顺序分配颜色，确保颜色稳定
-	for i, target := range t.targets {
-		if target == identifier {
-			return colorSequence[i%len(colorSequence)]
-		}
+	// 基于目标首次出现的顺序分配颜色，增删目标时已有目标的颜色保持不变
+	if index, exists := t.colorIndex[identifier]; exists {
+		return "[" + palette[index%len(palette)] + "]"
 	}
 
 	// 如果没找到，返回白色作为默认值
 	return "[white]"
 }
 
+// assignColors 为当前所有目标分配颜色序号
+func (t *TUI) assignColors() {
+	for _, target := range t.targets {
+		t.assignColor(target)
+	}
+}
+
+// assignColor 为目标分配颜色序号，已分配过的目标保持原有颜色
+func (t *TUI) assignColor(identifier string) {
+	if _, exists := t.colorIndex[identifier]; exists {
+		return
+	}
+	t.colorIndex[identifier] = t.nextColorIndex
+	t.nextColorIndex++
+}
+
+// drawOrder 返回图表中绘制目标的顺序，按颜色序号即目标首次出现的顺序排列
+// 与表格的排序无关，切换排序列不会改变折线重叠处的上下层次
+func (t *TUI) drawOrder(targetDataPoints map[string][]core.DataPoint) []string {
+	order := make([]string, 0, len(targetDataPoints))
+	for identifier := range targetDataPoints {
+		order = append(order, identifier)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, aok := t.colorIndex[order[i]]
+		b, bok := t.colorIndex[order[j]]
+		if aok != bok {
+			return aok
+		}
+		if a != b {
+			return a < b
+		}
+		return order[i] < order[j]
+	})
+	return order
+}
+
+// truncateTagged 将带颜色标签的文本截断到指定的显示宽度，颜色标签不会被截断
+func truncateTagged(text string, width int) string {
+	if tview.TaggedStringWidth(text) <= width {
+		return text
+	}
+	// 保留显示宽度不超过width的最长前缀；在颜色标签中间截断的前缀不会比补全标签后再多一个字符的前缀更窄，因此不会被选中
+	best := ""
+	for i := range text {
+		if prefix := text[:i]; tview.TaggedStringWidth(prefix) <= width {
+			best = prefix
+		}
+	}
+	return best
+}
+
 // abs 返回整数的绝对值
 func abs(x int) int {
 	if x < 0 {
@@ -78,19 +115,35 @@ func (t *TUI) updateIdentifiersForTest() {
 			identifiers = append(identifiers, target)
 		}
 	}
-	t.identifiers = identifiers
+	t.setIdentifiers(identifiers)
+}
+
+// targetStats 目标的统计数据，以及只供界面显示使用的延迟分布
+type targetStats struct {
+	*core.Stats
+	distribution *latencyHistogram // 自启动（或重置统计）以来的延迟分布
+	rollups      []rollupBucket    // 按固定时间桶聚合的长期历史，用于大时间窗口的显示
+}
 
-	// 确保选择状态正确
-	if t.selectedRow >= len(t.identifiers) {
-		t.selectedRow = len(t.identifiers) - 1
+// newTargetStats 创建目标的统计数据
+func newTargetStats(identifier string) *targetStats {
+	return &targetStats{
+		Stats:        core.NewStats(identifier),
+		distribution: newLatencyHistogram(),
 	}
 }
 
+// resetCounters 清零表格统计使用的累加器和延迟分布，图表历史保持不变
+func (s *targetStats) resetCounters() {
+	s.ResetCounters()
+	s.distribution = newLatencyHistogram()
+}
+
 // getOrCreateStats 获取或创建统计数据结构
-func (t *TUI) getOrCreateStats(identifier string) *core.Stats {
+func (t *TUI) getOrCreateStats(identifier string) *targetStats {
 	stats, exists := t.statsData[identifier]
 	if !exists {
-		stats = core.NewStats(identifier)
+		stats = newTargetStats(identifier)
 		t.statsData[identifier] = stats
 	}
 	return stats